@@ -3,9 +3,11 @@ package main
 import (
 	"image"
 	"image/color"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/unix-streamdeck/api"
@@ -14,56 +16,133 @@ import (
 type button struct {
 	widget.BaseWidget
 	editor *editor
+	device *api.StreamDeckInfo
+	area   *selectionArea
 
-	keyID int
-	key   api.Key
+	keyID    int
+	key      api.Key
+	modifier fyne.KeyModifier
+	focused  bool
+	toggleOn bool
+
+	refreshTimer *time.Timer
 }
 
-func newButton(key api.Key, id int, e *editor) *button {
-	b := &button{key: key, keyID: id, editor: e}
+func newButton(key api.Key, id int, device *api.StreamDeckInfo, e *editor) *button {
+	b := &button{key: key, keyID: id, device: device, editor: e}
 	b.ExtendBaseWidget(b)
 	return b
 }
 
 func (b *button) CreateRenderer() fyne.WidgetRenderer {
-	icon := canvas.NewImageFromFile(b.key.Icon)
+	icon := &canvas.Image{}
 	text := &canvas.Image{}
 
 	border := canvas.NewRectangle(color.Transparent)
 	border.StrokeWidth = 2
-	border.SetMinSize(fyne.NewSize(float32(b.editor.currentDevice.IconSize), float32(b.editor.currentDevice.IconSize)))
+	border.SetMinSize(fyne.NewSize(float32(b.device.IconSize), float32(b.device.IconSize)))
 
 	bg := canvas.NewRectangle(color.Black)
-	render := &buttonRenderer{border: border, text: text, icon: icon, bg: bg,
-		objects: []fyne.CanvasObject{bg, icon, text, border}, b: b}
+	longBadge := newBadge("L")
+	doubleBadge := newBadge("2×")
+	render := &buttonRenderer{border: border, text: text, icon: icon, bg: bg, longBadge: longBadge, doubleBadge: doubleBadge,
+		objects: []fyne.CanvasObject{bg, icon, text, border, longBadge, doubleBadge}, b: b}
 	render.Refresh()
 	return render
 }
 
 func (b *button) Tapped(ev *fyne.PointEvent) {
-	b.editor.editButton(b)
+	if b.device != b.editor.currentDevice {
+		b.editor.editButton(b)
+	} else if b.modifier&fyne.KeyModifierShift != 0 {
+		b.editor.selectRange(b)
+	} else if b.modifier&fyne.KeyModifierControl != 0 {
+		b.editor.toggleSelection(b)
+	} else {
+		b.editor.editButton(b)
+	}
+	b.editor.win.Canvas().Focus(b)
+}
+
+// MouseDown records the modifiers held for the tap that follows.
+func (b *button) MouseDown(ev *desktop.MouseEvent) {
+	b.modifier = ev.Modifier
+}
+
+func (b *button) MouseUp(ev *desktop.MouseEvent) {
+}
+
+// Dragged moves the current key to wherever it is dropped. Drags starting on
+// any other key draw the selection rubber band instead.
+func (b *button) Dragged(ev *fyne.DragEvent) {
+	e := b.editor
+	if e.drag == nil && b.area != nil && (b.area.dragging || b != e.currentButton || len(e.selection) > 0) {
+		forwarded := *ev
+		forwarded.Position = ev.Position.Add(b.Position())
+		b.area.Dragged(&forwarded)
+		return
+	}
+	e.dragKey(b, ev.AbsolutePosition)
+}
+
+func (b *button) DragEnd() {
+	if b.editor.drag == nil {
+		if b.area != nil {
+			b.area.DragEnd()
+		}
+		return
+	}
+	b.editor.dropKey()
+}
+
+// queueRefresh redraws the button once edits have paused for refreshDelay,
+// so typing into the form does not re-render the key on every keystroke.
+func (b *button) queueRefresh() {
+	if b.refreshTimer != nil {
+		b.refreshTimer.Stop()
+	}
+	b.refreshTimer = time.AfterFunc(refreshDelay, func() {
+		b.editor.events.post(b.Refresh)
+	})
+}
+
+// face returns the text and icon the grid shows for the button: those of the
+// state being previewed for a toggle key.
+func (b *button) face() (string, string) {
+	return toggleFace(b.key, b.toggleOn)
+}
+
+// flipToggle switches the preview of a toggle key to its other state.
+func (b *button) flipToggle() {
+	b.toggleOn = !b.toggleOn
+	b.Refresh()
 }
 
 func (b *button) updateKey() {
-	if b.keyID >= len(b.editor.currentDeviceConfig.Pages[b.editor.currentDevice.Page]) {
+	deck := b.editor.deckConfig(b.device.Serial)
+	if deck == nil || b.device.Page >= len(deck.Pages) || b.keyID >= len(deck.Pages[b.device.Page]) {
 		return
 	}
-	b.editor.currentDeviceConfig.Pages[b.editor.currentDevice.Page][b.keyID] = b.key
-	if b.editor.currentDeviceConfig.Pages[b.editor.currentDevice.Page][b.keyID].IconHandler == "Default" {
-		b.editor.currentDeviceConfig.Pages[b.editor.currentDevice.Page][b.keyID].IconHandler = ""
+	key := &deck.Pages[b.device.Page][b.keyID]
+	*key = b.key
+	if key.IconHandler == "Default" {
+		key.IconHandler = ""
 	}
-	if b.editor.currentDeviceConfig.Pages[b.editor.currentDevice.Page][b.keyID].KeyHandler == "Default" {
-		b.editor.currentDeviceConfig.Pages[b.editor.currentDevice.Page][b.keyID].KeyHandler = ""
+	if key.KeyHandler == "Default" {
+		key.KeyHandler = ""
 	}
 }
 
 const (
-	buttonInset = 2
+	buttonInset  = 2
+	refreshDelay = 150 * time.Millisecond
 )
 
 type buttonRenderer struct {
-	border, bg *canvas.Rectangle
-	icon, text *canvas.Image
+	border, bg             *canvas.Rectangle
+	icon, text             *canvas.Image
+	iconPath               string
+	longBadge, doubleBadge *canvas.Text
 
 	objects []fyne.CanvasObject
 
@@ -74,32 +153,70 @@ func (r *buttonRenderer) Layout(s fyne.Size) {
 	size := s.Subtract(fyne.NewSize(buttonInset*2, buttonInset*2))
 	offset := fyne.NewPos(buttonInset, buttonInset)
 
-	for _, obj := range r.objects {
+	for _, obj := range []fyne.CanvasObject{r.bg, r.icon, r.text, r.border} {
 		obj.Move(offset)
 		obj.Resize(size)
 	}
+
+	// gesture badges sit in the top right corner, long press outermost
+	x := s.Width - buttonInset*2
+	for _, badge := range []*canvas.Text{r.longBadge, r.doubleBadge} {
+		badgeSize := badge.MinSize()
+		x -= badgeSize.Width + buttonInset
+		badge.Move(fyne.NewPos(x, buttonInset*2))
+		badge.Resize(badgeSize)
+	}
 }
 
 func (r *buttonRenderer) MinSize() fyne.Size {
-	iconSize := fyne.NewSize(float32(r.b.editor.currentDevice.IconSize), float32(r.b.editor.currentDevice.IconSize))
+	iconSize := fyne.NewSize(float32(r.b.device.IconSize), float32(r.b.device.IconSize))
 	return iconSize.Add(fyne.NewSize(buttonInset*2, buttonInset*2))
 }
 
 func (r *buttonRenderer) Refresh() {
-	if r.b.editor.currentButton == r.b {
+	if drag := r.b.editor.drag; drag != nil && drag.target == r.b && drag.from != r.b {
+		r.border.StrokeColor = theme.SuccessColor()
+	} else if r.b.editor.currentButton == r.b && len(r.b.editor.selection) == 0 {
 		r.border.StrokeColor = theme.FocusColor()
+	} else if r.b.editor.isSelected(r.b) {
+		r.border.StrokeColor = theme.PrimaryColor()
 	} else {
 		r.border.StrokeColor = &color.Gray{128}
 	}
 
 	r.text.Image = r.textToImage()
 	r.text.Refresh()
-	if r.b.key.Icon != r.icon.File {
-		r.icon.File = r.b.key.Icon
-		go r.icon.Refresh()
+	if _, icon := r.b.face(); icon != r.iconPath {
+		r.iconPath = icon
+		go r.loadIcon(r.iconPath, r.b.device.IconSize)
 	}
 
+	r.border.StrokeWidth = 2
+	if r.b.focused {
+		r.border.StrokeWidth = 4
+	}
 	r.border.Refresh()
+
+	setVisible(r.longBadge, gestureBound(r.b.key, gestureLongPress))
+	setVisible(r.doubleBadge, gestureBound(r.b.key, gestureDoublePress))
+}
+
+// newBadge makes a gesture badge. Badges are dimmed because the daemon does
+// not run gestures yet.
+func newBadge(text string) *canvas.Text {
+	badge := canvas.NewText(text, theme.DisabledColor())
+	badge.TextSize = theme.CaptionTextSize()
+	badge.TextStyle = fyne.TextStyle{Bold: true}
+	badge.Hide()
+	return badge
+}
+
+func setVisible(obj fyne.CanvasObject, visible bool) {
+	if visible {
+		obj.Show()
+	} else {
+		obj.Hide()
+	}
 }
 
 func (r *buttonRenderer) BackgroundColor() color.Color {
@@ -114,11 +231,31 @@ func (r *buttonRenderer) Destroy() {
 	// nothing
 }
 
+// loadIcon decodes the icon off the main thread, then hands the image back
+// to the event loop, where it is dropped if the key has changed icon since.
+func (r *buttonRenderer) loadIcon(path string, size int) {
+	var img image.Image
+	if path != "" {
+		var err error
+		img, err = iconImage(path, size)
+		if err != nil {
+			logError(categoryUI, "Failed to load icon "+path, err)
+		}
+	}
+	r.b.editor.events.post(func() {
+		if path != r.iconPath {
+			return
+		}
+		r.icon.Image = img
+		r.icon.Refresh()
+	})
+}
+
 func (r *buttonRenderer) textToImage() image.Image {
-	textImg := image.NewNRGBA(image.Rect(0, 0, r.b.editor.currentDevice.IconSize, r.b.editor.currentDevice.IconSize))
-	img, err := api.DrawText(textImg, r.b.key.Text, r.b.key.TextSize, r.b.key.TextAlignment)
+	text, _ := r.b.face()
+	img, err := textImage(text, r.b.key.TextSize, r.b.key.TextAlignment, r.b.device.IconSize)
 	if err != nil {
-		fyne.LogError("Failed to draw text to imge", err)
+		logError(categoryUI, "Failed to draw text to image", err)
 	}
 	return img
 }
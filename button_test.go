@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+// TestButtonRendering compares the rendering of representative keys with
+// the golden images in testdata/button. When a rendering changes on purpose,
+// copy the new image from testdata/failed over the golden one.
+func TestButtonRendering(t *testing.T) {
+	info, deck := testDeck("A", api.Page{})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, ui := newTestEditor(t, f)
+
+	icon, err := navIconPath("home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		key  api.Key
+	}{
+		{"empty", api.Key{}},
+		{"text", api.Key{Text: "Mute"}},
+		{"text_top_large", api.Key{Text: "Big", TextSize: 24, TextAlignment: "TOP"}},
+		{"icon", api.Key{Icon: icon}},
+		{"icon_text", api.Key{Icon: icon, Text: "Home", TextAlignment: "BOTTOM"}},
+		{"gestures", api.Key{Text: "Hold", KeyHandlerFields: map[string]string{
+			gestureLongPress + gestureHandler: "Default", gestureDoublePress + gestureHandler: "Default"}}},
+		{"toggle", api.Key{Text: "Off", KeyHandlerFields: map[string]string{toggleField: `{"id":"t","off":"","on":"","on_text":"On"}`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newButton(tt.key, 0, e.currentDevice, e)
+			test.WidgetRenderer(b)
+			if tt.key.Icon != "" {
+				(<-ui)()
+			}
+			test.AssertObjectRendersToImage(t, "button/"+tt.name+".png", b)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"container/list"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	imageCacheSize = 512
+)
+
+// imageCache is a least-recently-used store of decoded icons and rendered
+// text overlays, so that refreshing a button only hits the disk or the font
+// rasteriser when something it depends on has actually changed.
+type imageCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[interface{}]*list.Element
+}
+
+type cacheEntry struct {
+	key interface{}
+	img image.Image
+}
+
+type iconCacheKey struct {
+	path    string
+	modTime time.Time
+	size    int
+}
+
+type textCacheKey struct {
+	text      string
+	size      int
+	alignment string
+	iconSize  int
+}
+
+var images = newImageCache(imageCacheSize)
+
+func newImageCache(capacity int) *imageCache {
+	return &imageCache{capacity: capacity, order: list.New(), items: make(map[interface{}]*list.Element)}
+}
+
+func (c *imageCache) get(key interface{}) (image.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).img, true
+}
+
+func (c *imageCache) put(key interface{}, img image.Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheEntry).img = img
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, img: img})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// iconImage returns the icon at path scaled to size, decoding it only if the
+// file is not cached or has been modified since it was last read.
+func iconImage(path string, size int) (image.Image, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	key := iconCacheKey{path: path, modTime: stat.ModTime(), size: size}
+	if img, ok := images.get(key); ok {
+		return img, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	img = api.ResizeImage(img, size)
+	images.put(key, img)
+	return img, nil
+}
+
+// textImage returns the transparent text overlay for a key of iconSize pixels.
+func textImage(text string, size int, alignment string, iconSize int) (image.Image, error) {
+	key := textCacheKey{text: text, size: size, alignment: alignment, iconSize: iconSize}
+	if img, ok := images.get(key); ok {
+		return img, nil
+	}
+
+	textImg := image.NewNRGBA(image.Rect(0, 0, iconSize, iconSize))
+	img, err := api.DrawText(textImg, text, size, alignment)
+	if err != nil {
+		return nil, err
+	}
+	images.put(key, img)
+	return img, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestImageCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newImageCache(2)
+	a, b, d := image.NewNRGBA(image.Rect(0, 0, 1, 1)), image.NewNRGBA(image.Rect(0, 0, 2, 2)), image.NewNRGBA(image.Rect(0, 0, 3, 3))
+	c.put("a", a)
+	c.put("b", b)
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("a is not cached")
+	}
+	c.put("d", d)
+	if _, ok := c.get("b"); ok {
+		t.Error("b was kept although it was used least recently")
+	}
+	if img, ok := c.get("a"); !ok || img != a {
+		t.Error("a was evicted although it was used since b")
+	}
+	if img, ok := c.get("d"); !ok || img != d {
+		t.Error("d is not cached")
+	}
+
+	c.put("a", b)
+	if img, _ := c.get("a"); img != b || c.order.Len() != 2 {
+		t.Errorf("replacing a left %d entries", c.order.Len())
+	}
+}
+
+func TestIconImageReloadsModifiedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "icon.png")
+	write := func(c color.Color, modTime time.Time) {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		img.Set(0, 0, c)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = png.Encode(f, img)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	now := time.Now()
+	write(color.White, now.Add(-time.Minute))
+	first, err := iconImage(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := iconImage(path, 4)
+	if again != first {
+		t.Error("an unchanged icon was decoded again")
+	}
+
+	write(color.Black, now)
+	changed, err := iconImage(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == first {
+		t.Error("a modified icon was served from the cache")
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	clipboardKeys = "keys"
+	clipboardPage = "page"
+)
+
+// clipboardContent is the JSON placed on the system clipboard when copying
+// keys or pages, so they can be pasted into another page, device or editor.
+type clipboardContent struct {
+	StreamDeckUI string    `json:"streamdeckui"`
+	Keys         []api.Key `json:"keys,omitempty"`
+	Page         api.Page  `json:"page,omitempty"`
+}
+
+func writeClipboard(content clipboardContent) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	fyne.CurrentApp().Clipboard().SetContent(string(data))
+	return nil
+}
+
+// parseClipboard reads copied keys or a page from text. Besides our own
+// format it accepts an array of keys, or a bare key object that has at least
+// one key property, so arbitrary JSON does not paste as an empty key.
+func parseClipboard(text string) (clipboardContent, error) {
+	text = strings.TrimSpace(text)
+	var content clipboardContent
+	if strings.HasPrefix(text, "[") {
+		err := json.Unmarshal([]byte(text), &content.Keys)
+		content.StreamDeckUI = clipboardKeys
+		return content, err
+	}
+	err := json.Unmarshal([]byte(text), &content)
+	if err != nil {
+		return content, err
+	}
+	if content.StreamDeckUI == "" {
+		if !hasKeyField(text) {
+			return content, errors.New("Clipboard does not contain keys or a page")
+		}
+		var key api.Key
+		err = json.Unmarshal([]byte(text), &key)
+		if err != nil {
+			return content, err
+		}
+		content = clipboardContent{StreamDeckUI: clipboardKeys, Keys: []api.Key{key}}
+	}
+	if content.StreamDeckUI != clipboardKeys && content.StreamDeckUI != clipboardPage {
+		return content, errors.New("Clipboard does not contain keys or a page")
+	}
+	return content, nil
+}
+
+// hasKeyField reports whether the JSON object text has a property of api.Key.
+func hasKeyField(text string) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(text), &fields) != nil {
+		return false
+	}
+	t := reflect.TypeOf(api.Key{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		if _, ok := fields[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy current or selected buttons. Used by both the toolbar action and the keyboard shortcut
+func (e *editor) copyButton() {
+	var keys []api.Key
+	for _, b := range e.selectedButtons() {
+		keys = append(keys, redactKey(b.key))
+	}
+	err := writeClipboard(clipboardContent{StreamDeckUI: clipboardKeys, Keys: keys})
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+}
+
+// copyPage copies every key of the current page.
+func (e *editor) copyPage() {
+	err := writeClipboard(clipboardContent{StreamDeckUI: clipboardPage, Page: redactPage(e.currentDeviceConfig.Pages[e.currentDevice.Page])})
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+}
+
+// Paste copied keys from the current button onwards, or replace the current
+// page with a copied page. Used by both the toolbar action and the keyboard shortcut
+func (e *editor) pasteButton() {
+	content, err := parseClipboard(fyne.CurrentApp().Clipboard().Content())
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+
+	page := e.currentDeviceConfig.Pages[e.currentDevice.Page]
+	start := 0
+	keys := content.Page
+	if content.StreamDeckUI == clipboardKeys {
+		if e.currentButton == nil {
+			return
+		}
+		start = e.currentButton.keyID
+		keys = content.Keys
+	}
+
+	var changes []keyChange
+	for i := start; i < len(page); i++ {
+		var after api.Key
+		if i-start < len(keys) {
+			after = keys[i-start]
+		} else if content.StreamDeckUI == clipboardKeys {
+			break
+		}
+		changes = append(changes, keyChange{serial: e.currentDevice.Serial, page: e.currentDevice.Page, index: i,
+			before: copyKey(page[i]), after: copyKey(after)})
+	}
+	e.applyChanges(changes)
+}
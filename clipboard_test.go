@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestCopyPasteAndUndo(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "copy me", Command: "echo hi"}, {Text: "keep"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	test.Tap(e.buttons[0].(*button))
+	e.copyButton()
+	test.Tap(e.buttons[4].(*button))
+	e.pasteButton()
+	e.saveConfig()
+
+	page := committedPage(t, f, "A", 0)
+	if page[4].Text != "copy me" || page[4].Command != "echo hi" {
+		t.Errorf("pasted key is %q running %q, want a copy of the first key", page[4].Text, page[4].Command)
+	}
+	if page[1].Text != "keep" || page[5].Text != "" {
+		t.Errorf("paste touched other keys: %q, %q", page[1].Text, page[5].Text)
+	}
+
+	e.undo()
+	e.saveConfig()
+	if page := committedPage(t, f, "A", 0); page[4].Text != "" {
+		t.Errorf("key after undoing paste is %q, want empty", page[4].Text)
+	}
+}
+
+func TestCopyPasteWholePage(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "a"}, {Text: "b"}}, api.Page{{Text: "old"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	e.copyPage()
+	e.setPage(1, false)
+	e.pasteButton()
+	e.saveConfig()
+
+	page := committedPage(t, f, "A", 1)
+	if page[0].Text != "a" || page[1].Text != "b" {
+		t.Errorf("pasted page starts %q, %q, want a, b", page[0].Text, page[1].Text)
+	}
+}
+
+func TestParseClipboard(t *testing.T) {
+	content, err := parseClipboard(`{"icon_handler": "Default", "text": "bare"}`)
+	if err != nil || len(content.Keys) != 1 || content.Keys[0].Text != "bare" {
+		t.Errorf("bare key parsed as %+v, %v", content, err)
+	}
+	for _, text := range []string{`{"foo": 1}`, `{}`, `"text"`} {
+		if _, err := parseClipboard(text); err == nil {
+			t.Errorf("%s was accepted as a key", text)
+		}
+	}
+}
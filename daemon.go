@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/unix-streamdeck/api"
+)
+
+// daemon is the part of the streamdeckd D-Bus API the editor uses.
+type daemon interface {
+	GetInfo() ([]*api.StreamDeckInfo, error)
+	SetPage(serial string, page int) error
+	GetConfig() (*api.Config, error)
+	SetConfig(config *api.Config) error
+	ReloadConfig() error
+	CommitConfig() error
+	GetModules() ([]*api.Module, error)
+	PressButton(serial string, keyIndex int) error
+	RegisterPageListener(cback func(string, int32)) error
+	Close()
+}
+
+// loggedDaemon records every daemon call, its duration and any error.
+type loggedDaemon struct {
+	daemon
+}
+
+func logCall(name string, start time.Time, err error) {
+	message := fmt.Sprintf("%s took %s", name, time.Since(start).Round(time.Microsecond))
+	if err != nil {
+		logError(categoryDaemon, message, err)
+		return
+	}
+	logDebug(categoryDaemon, message)
+}
+
+func (d *loggedDaemon) GetInfo() ([]*api.StreamDeckInfo, error) {
+	start := time.Now()
+	info, err := d.daemon.GetInfo()
+	logCall("GetInfo", start, err)
+	return info, err
+}
+
+func (d *loggedDaemon) SetPage(serial string, page int) error {
+	start := time.Now()
+	err := d.daemon.SetPage(serial, page)
+	logCall(fmt.Sprintf("SetPage(%s, %d)", serial, page), start, err)
+	return err
+}
+
+func (d *loggedDaemon) GetConfig() (*api.Config, error) {
+	start := time.Now()
+	c, err := d.daemon.GetConfig()
+	logCall("GetConfig", start, err)
+	return c, err
+}
+
+func (d *loggedDaemon) SetConfig(config *api.Config) error {
+	start := time.Now()
+	err := d.daemon.SetConfig(config)
+	logCall("SetConfig", start, err)
+	return err
+}
+
+func (d *loggedDaemon) ReloadConfig() error {
+	start := time.Now()
+	err := d.daemon.ReloadConfig()
+	logCall("ReloadConfig", start, err)
+	return err
+}
+
+func (d *loggedDaemon) CommitConfig() error {
+	start := time.Now()
+	err := d.daemon.CommitConfig()
+	logCall("CommitConfig", start, err)
+	return err
+}
+
+func (d *loggedDaemon) GetModules() ([]*api.Module, error) {
+	start := time.Now()
+	modules, err := d.daemon.GetModules()
+	logCall("GetModules", start, err)
+	return modules, err
+}
+
+func (d *loggedDaemon) PressButton(serial string, keyIndex int) error {
+	start := time.Now()
+	err := d.daemon.PressButton(serial, keyIndex)
+	logCall(fmt.Sprintf("PressButton(%s, %d)", serial, keyIndex), start, err)
+	return err
+}
+
+func (d *loggedDaemon) RegisterPageListener(cback func(string, int32)) error {
+	err := d.daemon.RegisterPageListener(func(serial string, page int32) {
+		logInfo(categoryPage, fmt.Sprintf("%s switched to page %d", serial, page+1))
+		cback(serial, page)
+	})
+	if err != nil {
+		logError(categoryDaemon, "Page listener stopped", err)
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	defaultBrightness = 50
+	noScreensaver     = "None"
+	never             = "Never"
+)
+
+// idleMinutes are the choices offered for the dim and sleep timeouts.
+var idleMinutes = []int{1, 2, 5, 10, 15, 30, 60}
+
+func idleOptions() []string {
+	options := []string{lang.L(never)}
+	for _, m := range idleMinutes {
+		if m == 1 {
+			options = append(options, lang.L("1 minute"))
+		} else {
+			options = append(options, fmt.Sprintf(lang.L("%d minutes"), m))
+		}
+	}
+	return options
+}
+
+func idleSelect(minutes int) *widget.Select {
+	sel := widget.NewSelect(idleOptions(), nil)
+	sel.SetSelectedIndex(0)
+	for i, m := range idleMinutes {
+		if m == minutes {
+			sel.SetSelectedIndex(i + 1)
+		}
+	}
+	return sel
+}
+
+func selectedMinutes(sel *widget.Select) int {
+	if i := sel.SelectedIndex(); i > 0 {
+		return idleMinutes[i-1]
+	}
+	return 0
+}
+
+// loadDeviceSettingsUI builds the form for the device settings of info; apply
+// stores the form in s. streamdeckd has no call to set the brightness, idle
+// timeouts or screensaver of a deck, so those are only kept in the editor's
+// settings until it does. The startup page is shown by applyStartupPages.
+func (e *editor) loadDeviceSettingsUI(info *api.StreamDeckInfo, s *deviceSettings) (ui fyne.CanvasObject, apply func()) {
+	note := widget.NewLabel(lang.L("The daemon cannot apply the brightness, idle timeouts or screensaver yet. They are saved with the editor's settings, and brightness keys keep working. The editor shows the startup page when it starts."))
+	note.Wrapping = fyne.TextWrapWord
+
+	brightness := widget.NewSlider(1, 100)
+	brightness.Step = 1
+	brightnessValue := widget.NewLabel("")
+	setDefault := widget.NewCheck(lang.L("Set brightness when the deck starts"), nil)
+	brightness.OnChanged = func(value float64) {
+		brightnessValue.SetText(fmt.Sprintf("%d%%", int(value)))
+	}
+	setDefault.OnChanged = func(on bool) {
+		if on {
+			brightness.Enable()
+		} else {
+			brightness.Disable()
+		}
+	}
+	if s.Brightness > 0 {
+		brightness.SetValue(float64(s.Brightness))
+		setDefault.SetChecked(true)
+	} else {
+		brightness.SetValue(defaultBrightness)
+		setDefault.SetChecked(false)
+	}
+	setDefault.OnChanged(setDefault.Checked)
+
+	dim := idleSelect(s.DimAfter)
+	sleep := idleSelect(s.SleepAfter)
+
+	screensaver := s.Screensaver
+	screensaverLabel := widget.NewLabel(lang.L(noScreensaver))
+	showScreensaver := func() {
+		if screensaver == "" {
+			screensaverLabel.SetText(lang.L(noScreensaver))
+		} else {
+			screensaverLabel.SetText(filepath.Base(screensaver))
+		}
+	}
+	showScreensaver()
+	selectScreensaver := widget.NewButton(lang.L("Select Image"), func() {
+		file, err := zenity.SelectFile(zenity.FileFilters{zenity.FileFilter{Name: "Files", Patterns: []string{"*.png", "*.jpg", "*.jpeg", "*.gif"}}})
+		if err != nil && err.Error() != "dialog canceled" {
+			dialog.ShowError(err, e.win)
+			return
+		}
+		if file != "" {
+			screensaver = file
+			showScreensaver()
+		}
+	})
+	clearScreensaver := widget.NewButton(lang.L("Clear"), func() {
+		screensaver = ""
+		showScreensaver()
+	})
+
+	var pages []string
+	if deck := e.deckConfig(info.Serial); deck != nil {
+		for p := range deck.Pages {
+			pages = append(pages, strconv.Itoa(p+1))
+		}
+	}
+	startup := widget.NewSelect(pages, nil)
+	if s.StartupPage < len(pages) {
+		startup.SetSelectedIndex(s.StartupPage)
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("", setDefault),
+		widget.NewFormItem(lang.L("Brightness"), container.NewBorder(nil, nil, nil, brightnessValue, brightness)),
+		widget.NewFormItem(lang.L("Dim After"), dim),
+		widget.NewFormItem(lang.L("Sleep After"), sleep),
+		widget.NewFormItem(lang.L("Screensaver"), container.NewHBox(screensaverLabel, selectScreensaver, clearScreensaver)),
+		widget.NewFormItem(lang.L("Startup Page"), startup),
+	)
+
+	apply = func() {
+		s.Brightness = 0
+		if setDefault.Checked {
+			s.Brightness = int(brightness.Value)
+		}
+		s.DimAfter = selectedMinutes(dim)
+		s.SleepAfter = selectedMinutes(sleep)
+		s.Screensaver = screensaver
+		s.StartupPage = 0
+		if startup.SelectedIndex() > 0 {
+			s.StartupPage = startup.SelectedIndex()
+		}
+	}
+	return container.NewVBox(form, note), apply
+}
+
+// applyStartupPages switches each deck with a startup page to it. The editor
+// learns of decks only when it connects, so this runs then, as each deck
+// appears to it. The first page needs nothing, the daemon starts there.
+func (e *editor) applyStartupPages() {
+	for _, info := range e.info {
+		settings, ok := e.settings.Decks[info.Serial]
+		if !ok {
+			continue
+		}
+		page := settings.Device.StartupPage
+		deck := e.deckConfig(info.Serial)
+		if page == 0 || page == info.Page || deck == nil || page >= len(deck.Pages) {
+			continue
+		}
+		err := conn.SetPage(info.Serial, page)
+		if err != nil {
+			logError(categoryDaemon, "Unable to show the startup page of "+info.Serial, err)
+			continue
+		}
+		info.Page = page
+	}
+}
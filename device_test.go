@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestDeviceSettingsApply(t *testing.T) {
+	info, deck := testDeck("A", api.Page{}, api.Page{})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	s := &e.settings.deck("A").Device
+	ui, apply := e.loadDeviceSettingsUI(e.currentDevice, s)
+
+	formItem(t, ui, "").(*widget.Check).SetChecked(true)
+	formItem(t, ui, "Brightness").(*fyne.Container).Objects[0].(*widget.Slider).SetValue(80)
+	formItem(t, ui, "Sleep After").(*widget.Select).SetSelected("10 minutes")
+	formItem(t, ui, "Startup Page").(*widget.Select).SetSelected("2")
+	if *s != (deviceSettings{}) {
+		t.Errorf("settings changed to %+v before applying", *s)
+	}
+
+	apply()
+	want := deviceSettings{Brightness: 80, SleepAfter: 10, StartupPage: 1}
+	if *s != want {
+		t.Errorf("applied settings are %+v, want %+v", *s, want)
+	}
+}
+
+func TestPageChangesRenumberStartupPage(t *testing.T) {
+	info, deck := testDeck("A", api.Page{}, api.Page{}, api.Page{})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	s := &e.settings.deck("A").Device
+	s.StartupPage = 2
+
+	e.movePage(2, 0)
+	if s.StartupPage != 0 {
+		t.Errorf("startup page is %d after moving it to the front, want 1", s.StartupPage+1)
+	}
+	e.movePage(1, 2)
+	if s.StartupPage != 0 {
+		t.Errorf("startup page is %d after moving another page, want 1", s.StartupPage+1)
+	}
+	s.StartupPage = 2
+	e.removePage(1)
+	if s.StartupPage != 1 {
+		t.Errorf("startup page is %d after removing a page before it, want 2", s.StartupPage+1)
+	}
+	e.removePage(1)
+	if s.StartupPage != 0 {
+		t.Errorf("startup page is %d after removing it, want 1", s.StartupPage+1)
+	}
+}
+
+func TestStartupPageIsShown(t *testing.T) {
+	infoA, deckA := testDeck("A", api.Page{}, api.Page{}, api.Page{})
+	infoB, deckB := testDeck("B", api.Page{}, api.Page{})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deckA, deckB}}, infoA, infoB)
+	e, _ := newTestEditor(t, f)
+	if len(f.shown) != 0 {
+		t.Fatalf("pages %v shown without startup pages", f.shown)
+	}
+
+	e.settings.deck("A").Device.StartupPage = 2
+	e.settings.deck("B").Device.StartupPage = 5
+	e.applyStartupPages()
+	want := []pageChange{{"A", 2}}
+	if len(f.shown) != 1 || f.shown[0] != want[0] {
+		t.Errorf("daemon was asked to show %v, want %v", f.shown, want)
+	}
+	if infoA.Page != 2 || infoB.Page != 0 {
+		t.Errorf("decks are on pages %d and %d, want 3 and 1", infoA.Page+1, infoB.Page+1)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+)
+
+// showDiagnostics opens the log panel, which updates live as entries arrive.
+func (e *editor) showDiagnostics() {
+	level := levelInfo
+	query := ""
+	entries := logs.filter(level, query)
+
+	list := widget.NewList(
+		func() int {
+			return len(entries)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			return label
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			label := item.(*widget.Label)
+			label.SetText(entries[id].String())
+			switch entries[id].level {
+			case levelError:
+				label.Importance = widget.DangerImportance
+			case levelWarning:
+				label.Importance = widget.WarningImportance
+			default:
+				label.Importance = widget.MediumImportance
+			}
+			label.Refresh()
+		})
+	update := func() {
+		entries = logs.filter(level, query)
+		list.Refresh()
+		list.ScrollToBottom()
+	}
+
+	levels := widget.NewSelect(levelNames, func(name string) {
+		for i, n := range levelNames {
+			if n == name {
+				level = logLevel(i)
+			}
+		}
+		update()
+	})
+	levels.SetSelected(level.String())
+	search := widget.NewEntry()
+	search.SetPlaceHolder(lang.L("Filter"))
+	search.OnChanged = func(text string) {
+		query = text
+		update()
+	}
+	export := widget.NewButtonWithIcon(lang.L("Export"), theme.DownloadIcon(), func() {
+		e.exportLog(logs.filter(level, query))
+	})
+
+	top := container.NewBorder(nil, nil, levels, export, search)
+	content := fyne.NewContainerWithLayout(layout.NewBorderLayout(top, nil, nil, nil), top, list)
+	d := dialog.NewCustom(lang.L("Diagnostics"), lang.L("Close"), content, e.win)
+	d.SetOnClosed(func() {
+		logs.setListener(nil)
+	})
+	// Entries are logged from any goroutine, including the main thread, so
+	// posting must not block; bursts of entries are folded into one update.
+	var pending atomic.Bool
+	logs.setListener(func() {
+		if pending.CompareAndSwap(false, true) {
+			go e.events.post(func() {
+				pending.Store(false)
+				update()
+			})
+		}
+	})
+	d.Resize(fyne.NewSize(900, 500))
+	d.Show()
+	list.ScrollToBottom()
+}
+
+// exportLog saves entries to a text file to attach to a bug report.
+func (e *editor) exportLog(entries []logEntry) {
+	file, err := zenity.SelectFileSave(zenity.ConfirmOverwrite(), zenity.Filename("streamdeckui-diagnostics.log"))
+	if err != nil && err.Error() != "dialog canceled" {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	if file == "" {
+		return
+	}
+	var text strings.Builder
+	for _, entry := range entries {
+		text.WriteString(entry.String())
+		text.WriteString("\n")
+	}
+	err = os.WriteFile(file, []byte(text.String()), 0600)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+// formItem returns the widget of the form item labelled label under root.
+func formItem(t *testing.T, root fyne.CanvasObject, label string) fyne.CanvasObject {
+	t.Helper()
+	var found fyne.CanvasObject
+	var walk func(obj fyne.CanvasObject)
+	walk = func(obj fyne.CanvasObject) {
+		switch obj := obj.(type) {
+		case *widget.Form:
+			for _, item := range obj.Items {
+				if item.Text == label && found == nil {
+					found = item.Widget
+				}
+				walk(item.Widget)
+			}
+		case *container.AppTabs:
+			for _, item := range obj.Items {
+				walk(item.Content)
+			}
+		case *fyne.Container:
+			for _, child := range obj.Objects {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+	if found == nil {
+		t.Fatalf("no form item %q", label)
+	}
+	return found
+}
+
+// formEntry returns the entry of the form item labelled label, looking
+// inside any container around it such as a variable preview.
+func formEntry(t *testing.T, root fyne.CanvasObject, label string) *widget.Entry {
+	t.Helper()
+	obj := formItem(t, root, label)
+	for {
+		switch o := obj.(type) {
+		case *widget.Entry:
+			return o
+		case *fyne.Container:
+			obj = o.Objects[0]
+		default:
+			t.Fatalf("form item %q is a %T, not an entry", label, obj)
+		}
+	}
+}
+
+// findButton returns the button labelled text anywhere under root.
+func findButton(t *testing.T, root fyne.CanvasObject, text string) *widget.Button {
+	t.Helper()
+	for _, obj := range test.LaidOutObjects(root) {
+		if b, ok := obj.(*widget.Button); ok && b.Text == text {
+			return b
+		}
+	}
+	t.Fatalf("no button %q", text)
+	return nil
+}
+
+func typeText(entry *widget.Entry, text string) {
+	entry.SetText("")
+	test.Type(entry, text)
+}
+
+// committedPage returns a page of a deck as last committed to the daemon.
+func committedPage(t *testing.T, f *fakeDaemon, serial string, page int) api.Page {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.committed == nil {
+		t.Fatal("config was not committed")
+	}
+	for _, deck := range f.committed.Decks {
+		if deck.Serial == serial {
+			if page >= len(deck.Pages) {
+				t.Fatalf("deck %s has %d pages, want page %d", serial, len(deck.Pages), page+1)
+			}
+			return deck.Pages[page]
+		}
+	}
+	t.Fatalf("deck %s was not committed", serial)
+	return nil
+}
+
+func pushedPages(f *fakeDaemon, serial string) []api.Page {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, deck := range f.config.Decks {
+		if deck.Serial == serial {
+			return deck.Pages
+		}
+	}
+	return nil
+}
+
+func TestTapButtonAndEditText(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "one"}, {Text: "two"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	test.Tap(e.buttons[1].(*button))
+	if e.currentButton != e.buttons[1] {
+		t.Fatal("tapped button is not being edited")
+	}
+	if e.iconHandler.Selected != "Default" {
+		t.Errorf("icon handler is %q, want Default", e.iconHandler.Selected)
+	}
+	text := formEntry(t, e.editorArea, "Text")
+	if text.Text != "two" {
+		t.Errorf("text field shows %q, want two", text.Text)
+	}
+
+	typeText(text, "Mute")
+	typeText(formEntry(t, e.editorArea, "Font Size"), "18")
+	e.saveConfig()
+
+	page := committedPage(t, f, "A", 0)
+	if page[0].Text != "one" {
+		t.Errorf("untouched key text is %q, want one", page[0].Text)
+	}
+	if page[1].Text != "Mute" || page[1].TextSize != 18 {
+		t.Errorf("edited key is %q size %d, want Mute size 18", page[1].Text, page[1].TextSize)
+	}
+	if page[1].IconHandler != "" || page[1].KeyHandler != "" {
+		t.Errorf("default handlers saved as %q/%q, want empty", page[1].IconHandler, page[1].KeyHandler)
+	}
+}
+
+func TestSwitchKeyHandler(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Command: "true"}, {}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	f.modules = []*api.Module{{Name: "Counter", IsKey: true, KeyFields: []api.Field{
+		{Title: "Step", Name: "step", Type: "Text"},
+		{Title: "Mode", Name: "mode", Type: "Select", Values: []string{"up", "down"}},
+	}}}
+	e, _ := newTestEditor(t, f)
+
+	test.Tap(e.buttons[1].(*button))
+	e.keyHandler.SetSelected("Counter")
+	typeText(formEntry(t, e.editorArea, "Step"), "5")
+	formItem(t, e.editorArea, "Mode").(*widget.Select).SetSelected("down")
+
+	test.Tap(e.buttons[0].(*button))
+	if e.keyHandler.Selected != "Default" {
+		t.Errorf("key handler of first key is %q, want Default", e.keyHandler.Selected)
+	}
+	if command := formEntry(t, e.editorArea, "Command"); command.Text != "true" {
+		t.Errorf("command field shows %q, want true", command.Text)
+	}
+
+	test.Tap(e.buttons[1].(*button))
+	if e.keyHandler.Selected != "Counter" {
+		t.Errorf("key handler of second key is %q, want Counter", e.keyHandler.Selected)
+	}
+	e.saveConfig()
+
+	key := committedPage(t, f, "A", 0)[1]
+	if key.KeyHandler != "Counter" {
+		t.Errorf("key handler saved as %q, want Counter", key.KeyHandler)
+	}
+	if key.KeyHandlerFields["step"] != "5" || key.KeyHandlerFields["mode"] != "down" {
+		t.Errorf("key fields saved as %v, want step 5 and mode down", key.KeyHandlerFields)
+	}
+}
+
+func TestAddAndRemovePages(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "first", SwitchPage: 3}}, api.Page{{Text: "second", SwitchPage: 2}}, api.Page{{Text: "third"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	e.addPage(api.Page{{Text: "fourth"}})
+	if e.currentDevice.Page != 3 {
+		t.Errorf("added page %d is not shown, page %d is", 4, e.currentDevice.Page+1)
+	}
+	if e.pageLabel.label.Text != "4/4" {
+		t.Errorf("page label is %q, want 4/4", e.pageLabel.label.Text)
+	}
+	if pages := pushedPages(f, "A"); len(pages) != 4 || pages[3][0].Text != "fourth" {
+		t.Fatalf("daemon has %d pages after adding one, want 4 ending in fourth", len(pages))
+	}
+
+	e.removePage(1)
+	pages := pushedPages(f, "A")
+	if len(pages) != 3 {
+		t.Fatalf("daemon has %d pages after removing one, want 3", len(pages))
+	}
+	if pages[0][0].SwitchPage != 2 {
+		t.Errorf("switch to the third page points at page %d after removing the second, want 2", pages[0][0].SwitchPage)
+	}
+	if pages[1][0].Text != "third" || pages[2][0].Text != "fourth" {
+		t.Errorf("pages after removal start %q, %q, want third, fourth", pages[1][0].Text, pages[2][0].Text)
+	}
+	if e.currentDevice.Page != 0 {
+		t.Errorf("showing page %d after removal, want 1", e.currentDevice.Page+1)
+	}
+}
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/lang"
+	"github.com/unix-streamdeck/api"
+)
+
+// Action UUIDs of the built in Elgato actions the importer understands.
+const (
+	elgatoHotkey       = "com.elgato.streamdeck.system.hotkey"
+	elgatoOpen         = "com.elgato.streamdeck.system.open"
+	elgatoWebsite      = "com.elgato.streamdeck.system.website"
+	elgatoText         = "com.elgato.streamdeck.system.text"
+	elgatoMulti        = "com.elgato.streamdeck.multiactions"
+	elgatoRoutine      = "com.elgato.streamdeck.multiactions.routine"
+	elgatoDelay        = "com.elgato.streamdeck.multiactions.delay"
+	elgatoOpenChild    = "com.elgato.streamdeck.profile.openchild"
+	elgatoBackToParent = "com.elgato.streamdeck.profile.backtoparent"
+	elgatoNextPage     = "com.elgato.streamdeck.page.next"
+	elgatoPrevPage     = "com.elgato.streamdeck.page.previous"
+)
+
+// elgatoManifest is the manifest.json of a profile or of one of its pages.
+// Older profiles keep the keys of the first page in Actions and each folder
+// in a child profile; newer ones list their pages and keep each page's keys
+// in a keypad controller.
+type elgatoManifest struct {
+	Name        string
+	Actions     map[string]elgatoAction
+	Controllers []struct {
+		Type    string
+		Actions map[string]elgatoAction
+	}
+	Pages struct {
+		Pages []string
+	}
+}
+
+type elgatoAction struct {
+	Name     string
+	UUID     string
+	State    int
+	States   []elgatoState
+	Settings json.RawMessage
+	// Actions holds the steps of a multi-action, grouped per state.
+	Actions []struct {
+		Actions []elgatoAction
+	}
+}
+
+type elgatoState struct {
+	Title          string
+	Image          string
+	FSize          elgatoNumber
+	TitleAlignment string
+	ShowTitle      *bool
+}
+
+// elgatoNumber accepts numbers written either as JSON numbers or strings.
+type elgatoNumber int
+
+func (n *elgatoNumber) UnmarshalJSON(data []byte) error {
+	i, err := strconv.ParseFloat(strings.Trim(string(data), `"`), 64)
+	if err != nil {
+		*n = 0
+		return nil
+	}
+	*n = elgatoNumber(i)
+	return nil
+}
+
+type elgatoHotkeySettings struct {
+	Hotkeys []struct {
+		KeyCmd    bool
+		KeyCtrl   bool
+		KeyOption bool
+		KeyShift  bool
+		VKeyCode  int
+		QTKeyCode int
+	}
+}
+
+type elgatoPathSettings struct {
+	Path string `json:"path"`
+}
+
+type elgatoTextSettings struct {
+	PastedText     string `json:"pastedText"`
+	IsSendingEnter bool   `json:"isSendingEnter"`
+}
+
+type elgatoChildSettings struct {
+	ProfileUUID string
+}
+
+type elgatoRoutineSettings struct {
+	Routine []elgatoAction
+}
+
+// elgatoReader walks the pages of an unpacked profile archive.
+type elgatoReader struct {
+	files      map[string]*zip.File
+	root       string
+	cols, rows int
+	profile    *importedProfile
+	children   map[string]int
+	// nextPages are the keys of next page actions, checked once the number
+	// of pages is known.
+	nextPages [][2]int
+}
+
+// readElgatoProfile reads a .streamDeckProfile archive, mapping its keys onto
+// a deck with the given number of columns and rows.
+func readElgatoProfile(file string, cols, rows int) (*importedProfile, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	r := &elgatoReader{files: make(map[string]*zip.File), cols: cols, rows: rows, children: make(map[string]int)}
+	for _, f := range archive.File {
+		r.files[strings.ToLower(f.Name)] = f
+		if path.Base(f.Name) == "manifest.json" && (r.root == "" || strings.Count(f.Name, "/") < strings.Count(r.root, "/")+1) {
+			r.root = path.Dir(f.Name)
+		}
+	}
+	if r.root == "" {
+		return nil, errors.New("No manifest.json found, this is not a Stream Deck profile")
+	}
+	var manifest elgatoManifest
+	err = r.readJSON(path.Join(r.root, "manifest.json"), &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	name := manifest.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	r.profile = &importedProfile{name: name}
+
+	if len(manifest.Pages.Pages) > 0 {
+		for p, id := range manifest.Pages.Pages {
+			dir := path.Join(r.root, "Profiles", id)
+			var page elgatoManifest
+			err = r.readJSON(path.Join(dir, "manifest.json"), &page)
+			if err != nil {
+				return nil, err
+			}
+			r.readPage(p, dir, keypadActions(page))
+		}
+	} else {
+		// Older profiles: folders are child profiles, which become extra
+		// pages as the keys opening them are found.
+		r.readPage(0, r.root, manifest.Actions)
+	}
+	r.checkNextPages()
+	return r.profile, nil
+}
+
+// checkNextPages clears the next page actions on the last page, which have
+// no page to go to.
+func (r *elgatoReader) checkNextPages() {
+	for _, position := range r.nextPages {
+		p, index := position[0], position[1]
+		key := &r.profile.pages[p][index]
+		if key.SwitchPage > len(r.profile.pages) {
+			key.SwitchPage = 0
+			r.profile.report(p, index, "next page action is on the last page and was left out")
+		}
+	}
+}
+
+func keypadActions(m elgatoManifest) map[string]elgatoAction {
+	for _, c := range m.Controllers {
+		if c.Type == "" || c.Type == "Keypad" {
+			return c.Actions
+		}
+	}
+	return m.Actions
+}
+
+func (r *elgatoReader) open(name string) (io.ReadCloser, error) {
+	f, ok := r.files[strings.ToLower(name)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return f.Open()
+}
+
+func (r *elgatoReader) readJSON(name string, v interface{}) error {
+	f, err := r.open(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
+
+// readPage maps the actions of page p, keyed by "column,row", onto its keys.
+func (r *elgatoReader) readPage(p int, dir string, actions map[string]elgatoAction) {
+	for len(r.profile.pages) <= p {
+		r.profile.pages = append(r.profile.pages, make(api.Page, r.cols*r.rows))
+	}
+	positions := make([]string, 0, len(actions))
+	for position := range actions {
+		positions = append(positions, position)
+	}
+	sort.Strings(positions)
+	for _, position := range positions {
+		action := actions[position]
+		var col, row int
+		_, err := fmt.Sscanf(position, "%d,%d", &col, &row)
+		if err != nil || col < 0 || row < 0 {
+			r.profile.unmapped = append(r.profile.unmapped, fmt.Sprintf(lang.L("Page %d: key at %q skipped, unknown position"), p+1, position))
+			continue
+		}
+		if col >= r.cols || row >= r.rows {
+			r.profile.unmapped = append(r.profile.unmapped, fmt.Sprintf(lang.L("Page %d: %s at column %d, row %d does not fit this deck"),
+				p+1, actionName(action), col+1, row+1))
+			continue
+		}
+		index := row*r.cols + col
+		r.profile.pages[p][index] = r.readKey(p, index, dir, position, action)
+	}
+}
+
+func actionName(action elgatoAction) string {
+	if action.Name != "" {
+		return action.Name
+	}
+	return action.UUID
+}
+
+func (r *elgatoReader) readKey(p, index int, dir, position string, action elgatoAction) api.Key {
+	var key api.Key
+	if len(action.States) > 0 {
+		state := action.State
+		if state < 0 || state >= len(action.States) {
+			state = 0
+		}
+		s := action.States[state]
+		if s.ShowTitle == nil || *s.ShowTitle {
+			key.Text = s.Title
+		}
+		key.TextSize = int(s.FSize)
+		switch strings.ToLower(s.TitleAlignment) {
+		case "top":
+			key.TextAlignment = "TOP"
+		case "bottom":
+			key.TextAlignment = "BOTTOM"
+		case "middle":
+			key.TextAlignment = "MIDDLE"
+		}
+		r.readImage(p, index, dir, position, state, s.Image)
+		if len(action.States) > 1 {
+			r.profile.report(p, index, "%s has %d states, only state %d was imported", actionName(action), len(action.States), state+1)
+		}
+	}
+
+	switch action.UUID {
+	case elgatoHotkey, elgatoText:
+		steps := r.steps(p, index, []elgatoAction{action})
+		if len(steps) == 1 && steps[0].Type == stepKeybind {
+			key.Keybind = steps[0].Value
+		} else if len(steps) > 0 {
+			r.setMacro(p, index, &key, steps)
+		}
+	case elgatoOpen:
+		var settings elgatoPathSettings
+		json.Unmarshal(action.Settings, &settings)
+		key.Command = "xdg-open " + shellQuote(settings.Path)
+		r.profile.report(p, index, "%s opens %q, check the path exists on this computer", actionName(action), settings.Path)
+	case elgatoWebsite:
+		var settings elgatoPathSettings
+		json.Unmarshal(action.Settings, &settings)
+		key.Url = settings.Path
+	case elgatoMulti, elgatoRoutine:
+		var steps []macroStep
+		if len(action.Actions) > 0 {
+			steps = r.steps(p, index, action.Actions[0].Actions)
+		} else {
+			var settings elgatoRoutineSettings
+			json.Unmarshal(action.Settings, &settings)
+			steps = r.steps(p, index, settings.Routine)
+		}
+		if len(steps) > 0 {
+			r.setMacro(p, index, &key, steps)
+		}
+	case elgatoOpenChild:
+		var settings elgatoChildSettings
+		json.Unmarshal(action.Settings, &settings)
+		child, ok := r.readChild(settings.ProfileUUID)
+		if !ok {
+			r.profile.report(p, index, "folder %s not found in the profile", settings.ProfileUUID)
+			break
+		}
+		key.SwitchPage = child + 1
+		key.KeyHandlerFields = map[string]string{folderField: "true"}
+	case elgatoBackToParent:
+		key.KeyHandlerFields = map[string]string{folderBackField: "true"}
+	case elgatoNextPage:
+		key.SwitchPage = p + 2
+		r.nextPages = append(r.nextPages, [2]int{p, index})
+	case elgatoPrevPage:
+		if p > 0 {
+			key.SwitchPage = p
+		}
+	case "":
+	default:
+		r.profile.report(p, index, "%s (%s) has no equivalent, only its title and image were imported", actionName(action), action.UUID)
+	}
+	return key
+}
+
+// readChild adds the pages of a folder in an older profile, returning the
+// page it opens on.
+func (r *elgatoReader) readChild(id string) (int, bool) {
+	if id == "" {
+		return 0, false
+	}
+	if p, ok := r.children[strings.ToLower(id)]; ok {
+		return p, true
+	}
+	dir := path.Join(r.root, "Profiles", id+".sdProfile")
+	var manifest elgatoManifest
+	err := r.readJSON(path.Join(dir, "manifest.json"), &manifest)
+	if err != nil {
+		return 0, false
+	}
+	p := len(r.profile.pages)
+	r.children[strings.ToLower(id)] = p
+	r.readPage(p, dir, keypadActions(manifest))
+	return p, true
+}
+
+// steps maps the actions of a multi-action onto macro steps.
+func (r *elgatoReader) steps(p, index int, actions []elgatoAction) []macroStep {
+	var steps []macroStep
+	for _, action := range actions {
+		switch action.UUID {
+		case elgatoHotkey:
+			var settings elgatoHotkeySettings
+			json.Unmarshal(action.Settings, &settings)
+			for _, hotkey := range settings.Hotkeys {
+				name := keyName(hotkey.VKeyCode, vkKeyNames)
+				if name == "" {
+					name = keyName(hotkey.QTKeyCode, qtKeyNames)
+				}
+				if name == "" {
+					if hotkey.VKeyCode > 0 || hotkey.QTKeyCode > 0 {
+						r.profile.report(p, index, "hotkey with key code %d could not be mapped", hotkey.VKeyCode)
+					}
+					continue
+				}
+				var parts []string
+				if hotkey.KeyCtrl {
+					parts = append(parts, "ctrl")
+				}
+				if hotkey.KeyOption {
+					parts = append(parts, "alt")
+				}
+				if hotkey.KeyShift {
+					parts = append(parts, "shift")
+				}
+				if hotkey.KeyCmd {
+					parts = append(parts, "super")
+				}
+				steps = append(steps, macroStep{Type: stepKeybind, Value: strings.Join(append(parts, name), "+")})
+			}
+		case elgatoText:
+			var settings elgatoTextSettings
+			json.Unmarshal(action.Settings, &settings)
+			steps = append(steps, macroStep{Type: stepType, Value: settings.PastedText})
+			if settings.IsSendingEnter {
+				steps = append(steps, macroStep{Type: stepKeybind, Value: "Return"})
+			}
+		case elgatoOpen:
+			var settings elgatoPathSettings
+			json.Unmarshal(action.Settings, &settings)
+			steps = append(steps, macroStep{Type: stepCommand, Value: "xdg-open " + shellQuote(settings.Path)})
+		case elgatoWebsite:
+			var settings elgatoPathSettings
+			json.Unmarshal(action.Settings, &settings)
+			steps = append(steps, macroStep{Type: stepURL, Value: settings.Path})
+		case elgatoDelay:
+			var settings map[string]interface{}
+			json.Unmarshal(action.Settings, &settings)
+			for name, value := range settings {
+				ms, ok := value.(float64)
+				if ok && strings.Contains(strings.ToLower(name), "delay") && len(steps) > 0 {
+					steps[len(steps)-1].DelayMs += int(ms)
+				}
+			}
+		default:
+			r.profile.report(p, index, "multi-action step %s (%s) has no equivalent and was left out", actionName(action), action.UUID)
+		}
+	}
+	return steps
+}
+
+// setMacro stores steps as the macro of key, reporting steps that do not
+// compile.
+func (r *elgatoReader) setMacro(p, index int, key *api.Key, steps []macroStep) {
+	err := setMacro(key, steps, "")
+	if err != nil {
+		r.profile.report(p, index, "macro could not be imported: %v", err)
+	}
+}
+
+// readImage reads the image of a key's state, trying where newer and older
+// profiles keep it, for the profile to save if it is imported.
+func (r *elgatoReader) readImage(p, index int, dir, position string, state int, image string) {
+	candidates := []string{path.Join(dir, position, "CustomImages", fmt.Sprintf("state%d.png", state))}
+	if image != "" {
+		candidates = append([]string{path.Join(dir, image), path.Join(dir, position, "CustomImages", image)}, candidates...)
+	}
+	for _, name := range candidates {
+		if _, ok := r.files[strings.ToLower(name)]; !ok {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(name))
+		if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+			r.profile.report(p, index, "image %s is not a PNG or JPEG and was left out", path.Base(name))
+			return
+		}
+		data, err := r.readFile(name)
+		if err != nil {
+			r.profile.report(p, index, "image could not be read: %v", err)
+			return
+		}
+		r.profile.icons = append(r.profile.icons, importedIcon{page: p, index: index, ext: ext, data: data})
+		return
+	}
+	if image != "" {
+		r.profile.report(p, index, "image %s not found in the profile", image)
+	}
+}
+
+func (r *elgatoReader) readFile(name string) ([]byte, error) {
+	f, err := r.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func keyName(code int, names map[int]string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	if code >= 'A' && code <= 'Z' {
+		return strings.ToLower(string(rune(code)))
+	}
+	if code >= '0' && code <= '9' {
+		return string(rune(code))
+	}
+	return ""
+}
+
+// vkKeyNames maps Windows virtual key codes to xdotool key names. Letters and
+// digits share their ASCII codes and are handled by keyName.
+var vkKeyNames = map[int]string{
+	8: "BackSpace", 9: "Tab", 13: "Return", 19: "Pause", 27: "Escape", 32: "space",
+	33: "Prior", 34: "Next", 35: "End", 36: "Home", 37: "Left", 38: "Up", 39: "Right", 40: "Down",
+	44: "Print", 45: "Insert", 46: "Delete",
+	96: "KP_0", 97: "KP_1", 98: "KP_2", 99: "KP_3", 100: "KP_4", 101: "KP_5", 102: "KP_6", 103: "KP_7", 104: "KP_8", 105: "KP_9",
+	106: "KP_Multiply", 107: "KP_Add", 109: "KP_Subtract", 110: "KP_Decimal", 111: "KP_Divide",
+	112: "F1", 113: "F2", 114: "F3", 115: "F4", 116: "F5", 117: "F6", 118: "F7", 119: "F8", 120: "F9", 121: "F10",
+	122: "F11", 123: "F12", 124: "F13", 125: "F14", 126: "F15", 127: "F16", 128: "F17", 129: "F18", 130: "F19",
+	131: "F20", 132: "F21", 133: "F22", 134: "F23", 135: "F24",
+	173: "XF86AudioMute", 174: "XF86AudioLowerVolume", 175: "XF86AudioRaiseVolume",
+	176: "XF86AudioNext", 177: "XF86AudioPrev", 178: "XF86AudioStop", 179: "XF86AudioPlay",
+	186: "semicolon", 187: "equal", 188: "comma", 189: "minus", 190: "period", 191: "slash", 192: "grave",
+	219: "bracketleft", 220: "backslash", 221: "bracketright", 222: "apostrophe",
+}
+
+// qtKeyNames maps the Qt key codes used by macOS profiles, where VKeyCode is
+// not set, to xdotool key names.
+var qtKeyNames = map[int]string{
+	0x20: "space", 0x01000000: "Escape", 0x01000001: "Tab", 0x01000003: "BackSpace", 0x01000004: "Return",
+	0x01000005: "KP_Enter", 0x01000006: "Insert", 0x01000007: "Delete", 0x01000010: "Home", 0x01000011: "End",
+	0x01000012: "Left", 0x01000013: "Up", 0x01000014: "Right", 0x01000015: "Down", 0x01000016: "Prior", 0x01000017: "Next",
+	0x01000030: "F1", 0x01000031: "F2", 0x01000032: "F3", 0x01000033: "F4", 0x01000034: "F5", 0x01000035: "F6",
+	0x01000036: "F7", 0x01000037: "F8", 0x01000038: "F9", 0x01000039: "F10", 0x0100003a: "F11", 0x0100003b: "F12",
+}
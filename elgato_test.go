@@ -0,0 +1,206 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+// writeProfile zips files, marshalling any value that is not already bytes.
+func writeProfile(t *testing.T, files map[string]interface{}) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "Test.streamDeckProfile")
+	out, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	archive := zip.NewWriter(out)
+	for name, content := range files {
+		data, ok := content.([]byte)
+		if !ok {
+			data, err = json.Marshal(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	err = archive.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+type jsonObject = map[string]interface{}
+
+func title(text string) []jsonObject {
+	return []jsonObject{{"Title": text}}
+}
+
+func TestReadElgatoProfile(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	root := "ABC.sdProfile/"
+	file := writeProfile(t, map[string]interface{}{
+		root + "manifest.json": jsonObject{"Name": "Work", "Pages": jsonObject{"Pages": []string{"p1", "p2"}}},
+		root + "Profiles/P1/manifest.json": jsonObject{"Controllers": []jsonObject{{"Type": "Keypad", "Actions": jsonObject{
+			"0,0": jsonObject{"UUID": elgatoHotkey, "States": []jsonObject{{"Title": "Copy", "Image": "Images/copy.png", "FSize": "14", "TitleAlignment": "bottom"}},
+				"Settings": jsonObject{"Hotkeys": []jsonObject{{"KeyCtrl": true, "VKeyCode": 67}, {"VKeyCode": -1}}}},
+			"1,0": jsonObject{"UUID": elgatoWebsite, "States": title("Docs"), "Settings": jsonObject{"path": "https://example.com"}},
+			"2,0": jsonObject{"UUID": elgatoMulti, "States": title("Build"), "Actions": []jsonObject{{"Actions": []jsonObject{
+				{"UUID": elgatoText, "Settings": jsonObject{"pastedText": "make", "isSendingEnter": true}},
+				{"UUID": elgatoDelay, "Settings": jsonObject{"delayInMs": 500}},
+				{"UUID": "com.example.plugin", "Name": "Plugin"},
+			}}}},
+			"0,1": jsonObject{"UUID": elgatoNextPage, "States": title("Next")},
+			"9,9": jsonObject{"UUID": elgatoHotkey, "Name": "Far away"},
+			"4,2": jsonObject{"UUID": "com.example.obs.scene", "Name": "Scene", "States": title("Live")},
+		}}}},
+		root + "Profiles/P1/Images/copy.png": testPNG(t),
+		root + "Profiles/P2/manifest.json": jsonObject{"Controllers": []jsonObject{{"Type": "Keypad", "Actions": jsonObject{
+			"0,0": jsonObject{"UUID": elgatoPrevPage, "States": title("Back")},
+			"1,0": jsonObject{"UUID": elgatoNextPage, "States": title("Next")},
+		}}}},
+	})
+
+	profile, err := readElgatoProfile(file, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.name != "Work" || len(profile.pages) != 2 {
+		t.Fatalf("read %q with %d pages, want Work with 2", profile.name, len(profile.pages))
+	}
+	page := profile.pages[0]
+	hotkey := page[0]
+	if hotkey.Text != "Copy" || hotkey.Keybind != "ctrl+c" || hotkey.TextSize != 14 || hotkey.TextAlignment != "BOTTOM" {
+		t.Errorf("hotkey imported as %+v", hotkey)
+	}
+	if _, err := os.Stat(filepath.Join(config, appDirName, importedIconsDir)); hotkey.Icon != "" || err == nil {
+		t.Errorf("image copied to %q before the import was confirmed", hotkey.Icon)
+	}
+	if page[1].Url != "https://example.com" {
+		t.Errorf("website imported with url %q", page[1].Url)
+	}
+	steps, err := parseMacro(page[2].KeyHandlerFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []macroStep{{Type: stepType, Value: "make"}, {Type: stepKeybind, Value: "Return", DelayMs: 500}}
+	if len(steps) != len(want) || steps[0] != want[0] || steps[1] != want[1] {
+		t.Errorf("multi-action imported as %+v, want %+v", steps, want)
+	}
+	if page[2].Command == "" {
+		t.Error("multi-action was not compiled into a command")
+	}
+	if page[5].SwitchPage != 2 || profile.pages[1][0].SwitchPage != 1 || profile.pages[1][1].SwitchPage != 0 {
+		t.Errorf("page actions switch to %d, %d and %d, want 2, 1 and none past the last page",
+			page[5].SwitchPage, profile.pages[1][0].SwitchPage, profile.pages[1][1].SwitchPage)
+	}
+	if page[14].Text != "Live" {
+		t.Errorf("plugin key title is %q, want Live", page[14].Text)
+	}
+
+	report := strings.Join(profile.unmapped, "\n")
+	for _, expected := range []string{"Far away", "Plugin", "com.example.obs.scene", "last page"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("report does not mention %s:\n%s", expected, report)
+		}
+	}
+
+	err = profile.saveIcons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	icon := profile.pages[0][0].Icon
+	if _, err := os.Stat(icon); err != nil {
+		t.Errorf("image not saved: %v", err)
+	}
+	again, err := readElgatoProfile(file, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = again.saveIcons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.pages[0][0].Icon == icon {
+		t.Errorf("importing the profile again saved its image over the first import's, at %s", icon)
+	}
+}
+
+func TestReadOlderElgatoProfileFolders(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	file := writeProfile(t, map[string]interface{}{
+		"ROOT.sdProfile/manifest.json": jsonObject{"Name": "Home", "Actions": jsonObject{
+			"1,0": jsonObject{"UUID": elgatoOpenChild, "States": title("Apps"), "Settings": jsonObject{"ProfileUUID": "CHILD"}},
+		}},
+		"ROOT.sdProfile/Profiles/CHILD.sdProfile/manifest.json": jsonObject{"Actions": jsonObject{
+			"0,0": jsonObject{"UUID": elgatoBackToParent},
+			"1,0": jsonObject{"UUID": elgatoOpen, "States": title("Term"), "Settings": jsonObject{"path": "/usr/bin/xterm"}},
+		}},
+		"ROOT.sdProfile/Profiles/CHILD.sdProfile/1,0/CustomImages/state0.png": testPNG(t),
+	})
+
+	profile, err := readElgatoProfile(file, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile.pages) != 2 {
+		t.Fatalf("read %d pages, want the root and its folder", len(profile.pages))
+	}
+	if folder := profile.pages[0][1]; !isFolderKey(folder) || folder.SwitchPage != 2 {
+		t.Errorf("folder key imported as %+v", folder)
+	}
+	if !isFolderBackKey(profile.pages[1][0]) {
+		t.Error("back to parent was not imported as a folder back key")
+	}
+	term := profile.pages[1][1]
+	if term.Command != "xdg-open '/usr/bin/xterm'" || len(profile.icons) != 1 || profile.icons[0].page != 1 || profile.icons[0].index != 1 {
+		t.Errorf("open action imported as %+v", term)
+	}
+}
+
+func TestImportPagesShiftsSwitchPage(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "existing"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	e.importPages([]api.Page{{{Text: "go", SwitchPage: 2}}, {{Text: "back", SwitchPage: 1}}})
+
+	pages := pushedPages(f, "A")
+	if len(pages) != 3 {
+		t.Fatalf("deck has %d pages after import, want 3", len(pages))
+	}
+	if pages[1][0].SwitchPage != 3 || pages[2][0].SwitchPage != 2 {
+		t.Errorf("imported keys switch to %d and %d, want 3 and 2", pages[1][0].SwitchPage, pages[2][0].SwitchPage)
+	}
+	if len(pages[1]) != 15 {
+		t.Errorf("imported page has %d keys, want 15", len(pages[1]))
+	}
+	if e.currentDevice.Page != 1 {
+		t.Errorf("showing page %d after import, want the first imported page", e.currentDevice.Page+1)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+const eventQueueSize = 64
+
+// eventLoop hands work from other goroutines, such as the daemon's page
+// listener and icon loading, to the Fyne main thread. Editor state and
+// widgets are only ever touched there, so they need no locking of their own;
+// anything running elsewhere must post to the loop instead.
+type eventLoop struct {
+	queue    chan func()
+	do       func(func())
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newEventLoop() *eventLoop {
+	return &eventLoop{queue: make(chan func(), eventQueueSize), do: fyne.Do, done: make(chan struct{})}
+}
+
+// post queues fn to run on the main thread after everything posted before
+// it. It is safe to call from any goroutine and does nothing once stopped.
+func (l *eventLoop) post(fn func()) {
+	select {
+	case l.queue <- fn:
+	case <-l.done:
+	}
+}
+
+// run delivers queued work in order until stop is called.
+func (l *eventLoop) run() {
+	for {
+		select {
+		case fn := <-l.queue:
+			l.do(fn)
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func (l *eventLoop) stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+	})
+}
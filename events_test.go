@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestEventLoopKeepsOrder(t *testing.T) {
+	const senders, events = 4, 100
+	ui := make(chan func())
+	loop := newEventLoop()
+	loop.do = func(fn func()) {
+		ui <- fn
+	}
+	go loop.run()
+	defer loop.stop()
+
+	var wg sync.WaitGroup
+	received := make([][]int, senders)
+	for s := 0; s < senders; s++ {
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			for i := 0; i < events; i++ {
+				loop.post(func() {
+					received[s] = append(received[s], i)
+				})
+			}
+		}(s)
+	}
+
+	for n := 0; n < senders*events; n++ {
+		(<-ui)()
+	}
+	wg.Wait()
+	for s := range received {
+		if len(received[s]) != events {
+			t.Fatalf("sender %d: got %d events, want %d", s, len(received[s]), events)
+		}
+		for i, got := range received[s] {
+			if got != i {
+				t.Fatalf("sender %d: event %d arrived as %d", s, got, i)
+			}
+		}
+	}
+}
+
+func TestEventLoopPostAfterStop(t *testing.T) {
+	loop := newEventLoop()
+	loop.stop()
+	loop.stop()
+	for i := 0; i < eventQueueSize*2; i++ {
+		loop.post(func() {
+			t.Error("ran after stop")
+		})
+	}
+}
+
+// TestPageListenerDuringEdits edits keys while the daemon reports page
+// changes for both decks, so the race detector sees any editor state or
+// widget touched from the D-Bus goroutine.
+func TestPageListenerDuringEdits(t *testing.T) {
+	const changes = 200
+	infoA, deckA := testDeck("A", api.Page{}, api.Page{}, api.Page{})
+	infoB, deckB := testDeck("B", api.Page{}, api.Page{})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deckA, deckB}}, infoA, infoB)
+	e, ui := newTestEditor(t, f)
+
+	icon, err := navIconPath("home")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for i := 0; i < changes; i++ {
+			f.pages <- pageChange{serial: "A", page: int32(i % 3)}
+			f.pages <- pageChange{serial: "B", page: int32(i % 2)}
+		}
+		f.pages <- pageChange{serial: "A", page: 2}
+		f.Close()
+	}()
+	go e.registerPageListener()
+
+	listening := f.listening
+	done := false
+	for step := 0; !done; step++ {
+		select {
+		case fn := <-ui:
+			fn()
+		case <-listening:
+			listening = nil
+			// Posting blocks while the queue is full, and only this
+			// goroutine drains it.
+			go e.events.post(func() {
+				done = true
+			})
+		}
+
+		b := e.buttons[step%len(e.buttons)].(*button)
+		if step%2 == 0 {
+			b.key.Text = fmt.Sprint(step)
+			b.updateKey()
+			b.queueRefresh()
+		} else {
+			b.key.Icon = icon
+			b.updateKey()
+			b.Refresh()
+		}
+	}
+
+	if e.currentDevice.Serial != "A" || e.currentDevice.Page != 2 {
+		t.Errorf("current device %s is on page %d, want A on page 2", e.currentDevice.Serial, e.currentDevice.Page+1)
+	}
+	if e.pageLabel.label.Text != "3/3" {
+		t.Errorf("page label is %q, want 3/3", e.pageLabel.label.Text)
+	}
+	if infoB.Page != (changes-1)%2 {
+		t.Errorf("deck B is on page %d, want %d", infoB.Page, (changes-1)%2)
+	}
+}
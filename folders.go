@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	folderField     = editorFieldPrefix + "folder"
+	folderBackField = editorFieldPrefix + "folder_back"
+
+	navBack = "back"
+)
+
+func isFolderKey(key api.Key) bool {
+	return key.KeyHandlerFields[folderField] == "true" && key.SwitchPage > 0
+}
+
+func isFolderBackKey(key api.Key) bool {
+	return key.KeyHandlerFields[folderBackField] == "true"
+}
+
+func folderBack(parent int) api.Key {
+	key := navigationKey(navBack, parent)
+	key.KeyHandlerFields = map[string]string{folderBackField: "true"}
+	return key
+}
+
+// backKeySlot returns the position of the back key on a folder page of size
+// keys: the first one not reserved for navigation, or -1 if there is none.
+func backKeySlot(size int, reserved map[int]bool) int {
+	for i := 0; i < size; i++ {
+		if !reserved[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// folderChildren returns the pages opened by folder keys on page.
+func folderChildren(deck *api.Deck, page int) []int {
+	var children []int
+	for _, key := range deck.Pages[page] {
+		if isFolderKey(key) && key.SwitchPage-1 < len(deck.Pages) {
+			children = append(children, key.SwitchPage-1)
+		}
+	}
+	return children
+}
+
+// folderRoots returns the pages that are not inside any folder.
+func folderRoots(deck *api.Deck) []int {
+	child := make(map[int]bool)
+	for p := range deck.Pages {
+		for _, c := range folderChildren(deck, p) {
+			if c != p {
+				child[c] = true
+			}
+		}
+	}
+	var roots []int
+	for p := range deck.Pages {
+		if !child[p] {
+			roots = append(roots, p)
+		}
+	}
+	return roots
+}
+
+// syncFolders points the back key of every folder page at the page holding
+// its folder key, adding the back key if it is missing, and clears back keys
+// whose folder no longer exists. A key in the way of a new back key moves to
+// a free position; a page with none left gets no back key.
+func syncFolders(deck *api.Deck, reserved map[int]bool) {
+	parents := make(map[int]int)
+	for p := range deck.Pages {
+		for _, c := range folderChildren(deck, p) {
+			if c != p {
+				parents[c] = p
+			}
+		}
+	}
+	for p := range deck.Pages {
+		parent, isChild := parents[p]
+		for i, key := range deck.Pages[p] {
+			if isFolderBackKey(key) && !isChild {
+				deck.Pages[p][i] = api.Key{}
+			}
+		}
+		if !isChild {
+			continue
+		}
+		found := false
+		for i, key := range deck.Pages[p] {
+			if isFolderBackKey(key) {
+				deck.Pages[p][i] = folderBack(parent)
+				found = true
+			}
+		}
+		if !found {
+			addBackKey(deck.Pages[p], folderBack(parent), reserved, p)
+		}
+	}
+}
+
+func addBackKey(page api.Page, back api.Key, reserved map[int]bool, index int) {
+	slot := backKeySlot(len(page), reserved)
+	if slot < 0 {
+		return
+	}
+	if !keyEmpty(page[slot]) {
+		free := -1
+		for i := range page {
+			if i != slot && !reserved[i] && keyEmpty(page[i]) {
+				free = i
+				break
+			}
+		}
+		if free < 0 {
+			logWarning(categoryUI, fmt.Sprintf("Page %d is full, so its folder has no back key", index+1))
+			return
+		}
+		page[free] = page[slot]
+	}
+	page[slot] = back
+}
+
+// makeFolder turns the current key into a folder, opening a new page that
+// has a back key returning to the current page.
+func (e *editor) makeFolder() {
+	b := e.currentButton
+	parent := e.currentDevice.Page
+	child := e.emptyPage()
+	addBackKey(child, folderBack(parent), e.settings.deck(e.currentDevice.Serial).Navigation.reserved(), len(e.currentDeviceConfig.Pages))
+	e.currentDeviceConfig.Pages = append(e.currentDeviceConfig.Pages, child)
+
+	b.key.SwitchPage = len(e.currentDeviceConfig.Pages)
+	if b.key.KeyHandlerFields == nil {
+		b.key.KeyHandlerFields = make(map[string]string)
+	}
+	b.key.KeyHandlerFields[folderField] = "true"
+	b.updateKey()
+	e.pagesChanged(parent)
+}
+
+// removeFolder turns a folder key back into a plain key. The folder's page
+// is kept, without its back key.
+func (e *editor) removeFolder() {
+	b := e.currentButton
+	delete(b.key.KeyHandlerFields, folderField)
+	b.key.SwitchPage = 0
+	b.updateKey()
+	e.pagesChanged(e.currentDevice.Page)
+}
+
+func (e *editor) loadFolderUI() fyne.CanvasObject {
+	if isFolderKey(e.currentButton.key) {
+		target := e.currentButton.key.SwitchPage - 1
+		return fyne.NewContainerWithLayout(layout.NewGridLayout(2),
+			widget.NewButton(lang.L("Open Folder"), func() {
+				e.setPage(target, true)
+			}),
+			widget.NewButton(lang.L("Remove Folder"), e.removeFolder))
+	}
+	return widget.NewButton(lang.L("Make Folder"), e.makeFolder)
+}
+
+// pageTreeChildren lists the nodes below uid in the page tree. Node IDs are
+// the path of page indices from the root, so that a page reachable from
+// several folders has a distinct node for each.
+func pageTreeChildren(deck *api.Deck, uid widget.TreeNodeID) []widget.TreeNodeID {
+	if uid == "" {
+		var ids []widget.TreeNodeID
+		for _, p := range folderRoots(deck) {
+			ids = append(ids, strconv.Itoa(p))
+		}
+		return ids
+	}
+	path := strings.Split(uid, "/")
+	page, _ := strconv.Atoi(path[len(path)-1])
+	var ids []widget.TreeNodeID
+	for _, c := range folderChildren(deck, page) {
+		cycle := false
+		for _, p := range path {
+			if p == strconv.Itoa(c) {
+				cycle = true
+			}
+		}
+		if !cycle {
+			ids = append(ids, uid+"/"+strconv.Itoa(c))
+		}
+	}
+	return ids
+}
+
+func pageTreePage(uid widget.TreeNodeID) int {
+	path := strings.Split(uid, "/")
+	page, _ := strconv.Atoi(path[len(path)-1])
+	return page
+}
+
+// showPageManager shows the pages of the current deck as a tree of folders,
+// with actions to switch to, move and remove pages.
+func (e *editor) showPageManager() {
+	deck := e.currentDeviceConfig
+	selected := -1
+	tree := widget.NewTree(
+		func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			return pageTreeChildren(deck, uid)
+		},
+		func(uid widget.TreeNodeID) bool {
+			return uid == "" || len(folderChildren(deck, pageTreePage(uid))) > 0
+		},
+		func(branch bool) fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(uid widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			page := pageTreePage(uid)
+			text := fmt.Sprintf(lang.L("Page %d"), page+1)
+			if page == e.currentDevice.Page {
+				text = fmt.Sprintf(lang.L("Page %d (current)"), page+1)
+			}
+			obj.(*widget.Label).SetText(text)
+		})
+	tree.OnSelected = func(uid widget.TreeNodeID) {
+		selected = pageTreePage(uid)
+	}
+	tree.OpenAllBranches()
+
+	refresh := func() {
+		deck = e.currentDeviceConfig
+		tree.UnselectAll()
+		selected = -1
+		tree.Refresh()
+		tree.OpenAllBranches()
+	}
+	actions := container.NewHBox(
+		widget.NewButtonWithIcon(lang.L("Show"), theme.VisibilityIcon(), func() {
+			if selected >= 0 {
+				e.setPage(selected, true)
+				refresh()
+			}
+		}),
+		widget.NewButtonWithIcon(lang.L("Move Up"), theme.MoveUpIcon(), func() {
+			if selected > 0 {
+				e.movePage(selected, selected-1)
+				refresh()
+			}
+		}),
+		widget.NewButtonWithIcon(lang.L("Move Down"), theme.MoveDownIcon(), func() {
+			if selected >= 0 {
+				e.movePage(selected, selected+1)
+				refresh()
+			}
+		}),
+		widget.NewButtonWithIcon(lang.L("Remove"), theme.DeleteIcon(), func() {
+			if selected < 0 {
+				return
+			}
+			page := selected
+			dialog.ShowConfirm(lang.L("Remove page?"), fmt.Sprintf(lang.L("Are you sure you want to remove page %d?"), page+1),
+				func(ok bool) {
+					if ok {
+						e.removePage(page)
+						refresh()
+					}
+				}, e.win)
+		}),
+	)
+
+	content := fyne.NewContainerWithLayout(layout.NewBorderLayout(nil, actions, nil, nil), actions, tree)
+	d := dialog.NewCustom(lang.L("Pages"), lang.L("Close"), content, e.win)
+	d.Resize(fyne.NewSize(400, 450))
+	d.Show()
+}
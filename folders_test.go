@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestSyncFoldersKeepsKeyInTheWay(t *testing.T) {
+	_, deck := testDeck("A", api.Page{{Text: "folder", SwitchPage: 2, KeyHandlerFields: map[string]string{folderField: "true"}}},
+		api.Page{{Text: "mine"}})
+	syncFolders(&deck, nil)
+	child := deck.Pages[1]
+	if !isFolderBackKey(child[0]) || child[0].SwitchPage != 1 {
+		t.Errorf("first key of the folder page is %+v, want a back key", child[0])
+	}
+	if child[1].Text != "mine" {
+		t.Errorf("the key in the way of the back key went to %+v", child[1])
+	}
+}
+
+func TestSyncFoldersAvoidsNavigationKeys(t *testing.T) {
+	_, deck := testDeck("A", api.Page{{}, {Text: "folder", SwitchPage: 2, KeyHandlerFields: map[string]string{folderField: "true"}}},
+		api.Page{})
+	nav := navigationSettings{Enabled: true, Previous: -1, Next: -1, Home: 0}
+	applyNavigation(&deck, nav)
+	syncFolders(&deck, nav.reserved())
+	if !isFolderBackKey(deck.Pages[1][1]) {
+		t.Errorf("back key is not next to the home key: %+v", deck.Pages[1][:2])
+	}
+}
+
+func TestSyncFoldersOnFullPage(t *testing.T) {
+	_, deck := testDeck("A", api.Page{{Text: "folder", SwitchPage: 2, KeyHandlerFields: map[string]string{folderField: "true"}}},
+		api.Page{})
+	for i := range deck.Pages[1] {
+		deck.Pages[1][i] = api.Key{Text: "full"}
+	}
+	syncFolders(&deck, nil)
+	for i, key := range deck.Pages[1] {
+		if key.Text != "full" {
+			t.Fatalf("key %d of a full folder page was replaced by %+v", i+1, key)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+// Secondary gestures bind extra actions to a key besides a normal press. A
+// gesture's handler and fields are kept in the key's KeyHandlerFields, under
+// the gesture's prefix. streamdeckd does not read them: it only reports key
+// presses, not how long a key was held or how soon it was pressed again, so
+// the actions are kept for a daemon that can run them but do nothing yet.
+const (
+	gestureLongPress   = editorFieldPrefix + "long_press."
+	gestureDoublePress = editorFieldPrefix + "double_press."
+
+	gestureHandler   = "handler"
+	gestureThreshold = "threshold"
+	gestureNone      = "None"
+
+	defaultLongPressMs = 500
+)
+
+// defaultGestureFields are the Default handler's actions, as fields.
+var defaultGestureFields = []api.Field{
+	{Title: "URL", Name: "url", Type: "Text"},
+	{Title: "Switch Page", Name: "switch_page", Type: "Number"},
+	{Title: "Keybind", Name: "keybind", Type: "Text"},
+	{Title: "Command", Name: "command", Type: "Text"},
+	{Title: "Brightness", Name: "brightness", Type: "Number"},
+}
+
+// gestureBound reports whether key has an action for the gesture.
+func gestureBound(key api.Key, gesture string) bool {
+	handler := key.KeyHandlerFields[gesture+gestureHandler]
+	return handler != "" && handler != gestureNone
+}
+
+// remapGesturePage rewrites the switch_page action of key's gesture as
+// remapSwitchPages does, removing it if its page is gone.
+func remapGesturePage(key *api.Key, gesture string, mapping func(page int) int) {
+	name := gesture + "switch_page"
+	page, err := strconv.Atoi(key.KeyHandlerFields[name])
+	if err != nil || page < 1 || key.KeyHandlerFields[gesture+gestureHandler] != "Default" {
+		return
+	}
+	page = mapping(page-1) + 1
+	if page == 0 {
+		delete(key.KeyHandlerFields, name)
+		return
+	}
+	key.KeyHandlerFields[name] = strconv.Itoa(page)
+}
+
+func gestureFields(handler, gesture string) []api.Field {
+	fields := localiseFields(handler, defaultGestureFields)
+	if handler != "Default" {
+		module := findModule(handler)
+		if module == nil {
+			return nil
+		}
+		fields = module.KeyFields
+	}
+	var prefixed []api.Field
+	for _, field := range fields {
+		field.Name = gesture + field.Name
+		prefixed = append(prefixed, field)
+	}
+	return prefixed
+}
+
+func loadGestureUI(e *editor, gesture string) fyne.CanvasObject {
+	itemMap := e.currentButton.key.KeyHandlerFields
+	fields := container.NewMax()
+	showFields := func(handler string) {
+		fields.Objects = nil
+		if handler != "" && handler != gestureNone {
+			fields.Objects = []fyne.CanvasObject{loadUI(gestureFields(handler, gesture), itemMap, e)}
+		}
+		fields.Refresh()
+	}
+
+	options := []string{lang.L(gestureNone)}
+	for _, module := range handlers {
+		if module.IsKey {
+			options = append(options, module.Name)
+		}
+	}
+	handler := widget.NewSelect(options, func(name string) {
+		if name == lang.L(gestureNone) {
+			name = gestureNone
+		}
+		if name == gestureNone {
+			delete(itemMap, gesture+gestureHandler)
+		} else {
+			itemMap[gesture+gestureHandler] = name
+		}
+		e.currentButton.updateKey()
+		e.currentButton.Refresh()
+		showFields(name)
+	})
+	current := itemMap[gesture+gestureHandler]
+	if current == "" {
+		current = lang.L(gestureNone)
+	}
+	handler.SetSelected(current)
+
+	form := widget.NewForm(widget.NewFormItem(lang.L("Handler"), handler))
+	if gesture == gestureLongPress {
+		threshold := widget.NewEntry()
+		threshold.SetPlaceHolder(strconv.Itoa(defaultLongPressMs))
+		threshold.SetText(itemMap[gesture+gestureThreshold])
+		threshold.OnChanged = func(text string) {
+			if text == "" {
+				delete(itemMap, gesture+gestureThreshold)
+				e.currentButton.updateKey()
+				return
+			}
+			ms, err := strconv.Atoi(text)
+			if err != nil {
+				dialog.ShowError(err, e.win)
+				return
+			}
+			itemMap[gesture+gestureThreshold] = strconv.Itoa(ms)
+			e.currentButton.updateKey()
+		}
+		form.Append(lang.L("Hold (ms)"), threshold)
+	}
+	return container.NewVBox(form, fields)
+}
+
+// loadGesturesUI builds the long and double press sections of the
+// Keypress Config tab.
+func loadGesturesUI(e *editor) fyne.CanvasObject {
+	note := widget.NewLabel(lang.L("The daemon does not run long and double press actions yet."))
+	note.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(
+		note,
+		widget.NewCard("", lang.L("Long Press"), loadGestureUI(e, gestureLongPress)),
+		widget.NewCard("", lang.L("Double Press"), loadGestureUI(e, gestureDoublePress)),
+	)
+}
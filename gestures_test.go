@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestPageChangesRenumberGestures(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{KeyHandlerFields: map[string]string{
+		gestureLongPress + gestureHandler: "Default", gestureLongPress + "switch_page": "3",
+		gestureDoublePress + gestureHandler: "Default", gestureDoublePress + "switch_page": "2",
+	}}}, api.Page{{}}, api.Page{{}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	e.removePage(1)
+	fields := pushedPages(f, "A")[0][0].KeyHandlerFields
+	if fields[gestureLongPress+"switch_page"] != "2" {
+		t.Errorf("long press switches to page %q after removing the second, want 2", fields[gestureLongPress+"switch_page"])
+	}
+	if page, ok := fields[gestureDoublePress+"switch_page"]; ok {
+		t.Errorf("double press still switches to page %q after its page was removed", page)
+	}
+}
@@ -2,42 +2,60 @@ package main
 
 import (
 	"errors"
-	"log"
 	"strconv"
 	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 	"github.com/ncruces/zenity"
 	"github.com/unix-streamdeck/api"
 )
 
+// editorFieldPrefix namespaces the fields the editor keeps in a key's handler
+// fields for itself, such as folder markers, macros and toggles, so they
+// cannot clash with the fields of a key handler module.
+const editorFieldPrefix = "streamdeckui."
+
+// isEditorField reports whether the handler field name is one of the
+// editor's own.
+func isEditorField(name string) bool {
+	return strings.HasPrefix(name, editorFieldPrefix)
+}
+
 var (
 	handlers = []*api.Module{
 		{Name: "Default", IsIcon: true, IsKey: true},
 	}
 )
 
-func initHandlers(conn *api.Connection) {
+func initHandlers(conn daemon) {
 	modules, err := conn.GetModules()
 	if err != nil {
-		fyne.LogError("Unable to get handlers", err)
+		logError(categoryDaemon, "Unable to get handlers", err)
+	}
+	for _, module := range modules {
+		localised := *module
+		localised.IconFields = localiseFields(module.Name, module.IconFields)
+		localised.KeyFields = localiseFields(module.Name, module.KeyFields)
+		handlers = append(handlers, &localised)
 	}
-	handlers = append(handlers, modules...)
 }
 
 func loadDefaultIconUI(e *editor) fyne.CanvasObject {
 
 	entry := widget.NewMultiLineEntry()
+	entryPreview, previewText := e.variablePreview(entry, e.currentButton.key.Text, false)
 	entry.OnChanged = func(text string) {
+		previewText(text)
 		e.currentButton.key.Text = text
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 
-	icon := widget.NewButton("Select Icon", func() {
+	icon := widget.NewButton(lang.L("Select Icon"), func() {
 		file, err := zenity.SelectFile(zenity.FileFilters{zenity.FileFilter{Name: "Files", Patterns: []string{"*.png", "*.jpg", "*.jpeg"}}})
 		if err != nil && err.Error() != "dialog canceled" {
 			dialog.ShowError(err, e.win)
@@ -50,7 +68,7 @@ func loadDefaultIconUI(e *editor) fyne.CanvasObject {
 		}
 	})
 
-	clearIcon := widget.NewButton("Clear Icon", func() {
+	clearIcon := widget.NewButton(lang.L("Clear Icon"), func() {
 		e.currentButton.key.Icon = ""
 		e.currentButton.Refresh()
 		e.currentButton.updateKey()
@@ -68,8 +86,8 @@ func loadDefaultIconUI(e *editor) fyne.CanvasObject {
 	textSize.OnChanged = func(size string) {
 		if size == "" {
 			e.currentButton.key.TextSize = 0
-			e.currentButton.Refresh()
 			e.currentButton.updateKey()
+			e.currentButton.queueRefresh()
 			return
 		}
 		sizeInt, err := strconv.Atoi(size)
@@ -78,8 +96,8 @@ func loadDefaultIconUI(e *editor) fyne.CanvasObject {
 			return
 		}
 		e.currentButton.key.TextSize = sizeInt
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 
 	entry.SetText(e.currentButton.key.Text)
@@ -90,12 +108,12 @@ func loadDefaultIconUI(e *editor) fyne.CanvasObject {
 	}
 	textAlignment.SetSelected(strings.ToUpper(e.currentButton.key.TextAlignment))
 
-	return widget.NewForm(
-		widget.NewFormItem("Text", entry),
-		widget.NewFormItem("Text Alignment", textAlignment),
-		widget.NewFormItem("Font Size", textSize),
-		widget.NewFormItem("Icon", iconGroup),
-	)
+	return e.loadToggleIconUI(widget.NewForm(
+		widget.NewFormItem(lang.L("Text"), entryPreview),
+		widget.NewFormItem(lang.L("Text Alignment"), textAlignment),
+		widget.NewFormItem(lang.L("Font Size"), textSize),
+		widget.NewFormItem(lang.L("Icon"), iconGroup),
+	))
 }
 
 func loadDefaultKeyUI(e *editor) fyne.CanvasObject {
@@ -108,13 +126,18 @@ func loadDefaultKeyUI(e *editor) fyne.CanvasObject {
 	keyBind.Text = e.currentButton.key.Keybind
 	command := widget.NewEntry()
 	command.Text = e.currentButton.key.Command
+	if fields := e.currentButton.key.KeyHandlerFields; fields[macroField] != "" || fields[toggleField] != "" {
+		command.Disable()
+	}
 	brightness := widget.NewEntry()
 	brightness.Text = strconv.FormatInt(int64(e.currentButton.key.Brightness), 10)
 
+	urlPreview, previewURL := e.variablePreview(url, url.Text, false)
 	url.OnChanged = func(text string) {
+		previewURL(text)
 		e.currentButton.key.Url = text
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 
 	page.OnChanged = func(text string) {
@@ -128,20 +151,22 @@ func loadDefaultKeyUI(e *editor) fyne.CanvasObject {
 			pageNum = int(num)
 		}
 		e.currentButton.key.SwitchPage = pageNum
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 
 	keyBind.OnChanged = func(text string) {
 		e.currentButton.key.Keybind = text
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 
+	commandPreview, previewCommand := e.variablePreview(command, command.Text, true)
 	command.OnChanged = func(text string) {
+		previewCommand(text)
 		e.currentButton.key.Command = text
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 
 	brightness.OnChanged = func(text string) {
@@ -153,21 +178,24 @@ func loadDefaultKeyUI(e *editor) fyne.CanvasObject {
 				return
 			}
 			if int(num) > 100 || int(num) < 0 {
-				dialog.ShowError(errors.New("Brightness out of range"), e.win)
+				dialog.ShowError(errors.New(lang.L("Brightness out of range")), e.win)
 				return
 			}
 			brightness = int(num)
 		}
 		e.currentButton.key.Brightness = brightness
-		e.currentButton.Refresh()
 		e.currentButton.updateKey()
+		e.currentButton.queueRefresh()
 	}
 	return widget.NewForm(
-		widget.NewFormItem("URL", url),
-		widget.NewFormItem("Switch Page", page),
-		widget.NewFormItem("Keybind", keyBind),
-		widget.NewFormItem("Command", command),
-		widget.NewFormItem("Brightness", brightness),
+		widget.NewFormItem(lang.L("URL"), urlPreview),
+		widget.NewFormItem(lang.L("Switch Page"), page),
+		widget.NewFormItem(lang.L("Keybind"), keyBind),
+		widget.NewFormItem(lang.L("Command"), commandPreview),
+		widget.NewFormItem(lang.L("Brightness"), brightness),
+		widget.NewFormItem(lang.L("Folder"), e.loadFolderUI()),
+		widget.NewFormItem(lang.L("Macro"), e.loadMacroUI()),
+		widget.NewFormItem(lang.L("Toggle"), e.loadToggleUI()),
 	)
 }
 
@@ -186,15 +214,16 @@ func generateField(field api.Field, itemMap map[string]string, e *editor) *widge
 	if field.Type == "Text" {
 		item := widget.NewEntry()
 		item.Text = itemMap[field.Name]
+		preview, previewText := e.variablePreview(item, item.Text, false)
 		item.OnChanged = func(text string) {
+			previewText(text)
 			itemMap[field.Name] = text
-			log.Println(e.currentButton.key.IconHandlerFields["check_command"])
-			e.currentButton.Refresh()
 			e.currentButton.updateKey()
+			e.currentButton.queueRefresh()
 		}
-		return widget.NewFormItem(field.Title, item)
+		return widget.NewFormItem(field.Title, preview)
 	} else if field.Type == "File" {
-		file := widget.NewButton("Select File", func() {
+		file := widget.NewButton(lang.L("Select File"), func() {
 			var fileTypes []string
 			for _, fileType := range field.FileTypes {
 				fileTypes = append(fileTypes, "*"+fileType)
@@ -210,7 +239,7 @@ func generateField(field api.Field, itemMap map[string]string, e *editor) *widge
 				e.currentButton.updateKey()
 			}
 		})
-		clearFile := widget.NewButton("Clear File", func() {
+		clearFile := widget.NewButton(lang.L("Clear File"), func() {
 			itemMap[field.Name] = ""
 			e.currentButton.Refresh()
 			e.currentButton.updateKey()
@@ -242,8 +271,8 @@ func generateField(field api.Field, itemMap map[string]string, e *editor) *widge
 				value = int(num)
 			}
 			itemMap[field.Name] = strconv.Itoa(value)
-			e.currentButton.Refresh()
 			e.currentButton.updateKey()
+			e.currentButton.queueRefresh()
 		}
 		return widget.NewFormItem(field.Title, item)
 	} else if field.Type == "Select" {
@@ -258,15 +287,7 @@ func generateField(field api.Field, itemMap map[string]string, e *editor) *widge
 		}
 		return widget.NewFormItem(field.Title, item)
 	} else if field.Type == "Password" {
-		item := widget.NewPasswordEntry()
-		item.Text = itemMap[field.Name]
-		item.OnChanged = func(text string) {
-			itemMap[field.Name] = text
-			log.Println(e.currentButton.key.IconHandlerFields["check_command"])
-			e.currentButton.Refresh()
-			e.currentButton.updateKey()
-		}
-		return widget.NewFormItem(field.Title, item)
+		return widget.NewFormItem(field.Title, e.loadPasswordUI(field.Name, itemMap))
 	}
 	return nil
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+// fakeDaemon stands in for streamdeckd. It keeps the config it is given and
+// delivers the page changes sent on pages to the registered listener.
+type fakeDaemon struct {
+	mu        sync.Mutex
+	info      []*api.StreamDeckInfo
+	config    *api.Config
+	committed *api.Config
+	modules   []*api.Module
+	pressed   []int
+	shown     []pageChange
+
+	pages     chan pageChange
+	listening chan struct{}
+}
+
+type pageChange struct {
+	serial string
+	page   int32
+}
+
+func newFakeDaemon(config *api.Config, info ...*api.StreamDeckInfo) *fakeDaemon {
+	return &fakeDaemon{info: info, config: config, pages: make(chan pageChange), listening: make(chan struct{})}
+}
+
+func (f *fakeDaemon) GetInfo() ([]*api.StreamDeckInfo, error) {
+	return f.info, nil
+}
+
+func (f *fakeDaemon) SetPage(serial string, page int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.shown = append(f.shown, pageChange{serial, int32(page)})
+	return nil
+}
+
+func (f *fakeDaemon) GetConfig() (*api.Config, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return cloneConfig(f.config)
+}
+
+func (f *fakeDaemon) SetConfig(config *api.Config) error {
+	c, err := cloneConfig(config)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = c
+	return nil
+}
+
+func (f *fakeDaemon) ReloadConfig() error {
+	return nil
+}
+
+func (f *fakeDaemon) CommitConfig() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.committed = f.config
+	return nil
+}
+
+func (f *fakeDaemon) GetModules() ([]*api.Module, error) {
+	return f.modules, nil
+}
+
+func (f *fakeDaemon) PressButton(serial string, keyIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pressed = append(f.pressed, keyIndex)
+	return nil
+}
+
+func (f *fakeDaemon) RegisterPageListener(cback func(string, int32)) error {
+	defer close(f.listening)
+	for change := range f.pages {
+		cback(change.serial, change.page)
+	}
+	return nil
+}
+
+func (f *fakeDaemon) Close() {
+	close(f.pages)
+}
+
+// testDeck returns the info and config of a 3x5 deck with the given pages.
+func testDeck(serial string, pages ...api.Page) (*api.StreamDeckInfo, api.Deck) {
+	info := &api.StreamDeckInfo{Cols: 5, Rows: 3, IconSize: 72, Serial: serial}
+	for i := range pages {
+		for len(pages[i]) < info.Cols*info.Rows {
+			pages[i] = append(pages[i], api.Key{})
+		}
+	}
+	return info, api.Deck{Serial: serial, Pages: pages}
+}
+
+// newTestEditor builds the full editor UI in a test window against f, with
+// the user's config, cache and secret store redirected to a temporary
+// directory. The event loop is running, but hands its work to ui, which the
+// test drains on its own goroutine in place of the Fyne main thread. The
+// page listener is left for the test to start.
+func newTestEditor(t *testing.T, f *fakeDaemon) (*editor, chan func()) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir+"/config")
+	t.Setenv("XDG_CACHE_HOME", dir+"/cache")
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+dir+"/no-bus")
+	t.Setenv("LC_ALL", "en_GB.UTF-8")
+	t.Setenv("LANGUAGE", "en")
+
+	test.NewTempApp(t)
+	conn = f
+	handlers = handlers[:1]
+	w := test.NewTempWindow(t, nil)
+	w.Resize(fyne.NewSize(1200, 800))
+
+	e := newEditor(f.info, w)
+	ui := make(chan func(), eventQueueSize)
+	e.events.do = func(fn func()) {
+		ui <- fn
+	}
+	go e.events.run()
+	t.Cleanup(e.events.stop)
+	w.SetContent(e.loadUI())
+	return e, ui
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	historyDir         = "history"
+	historyConfigFile  = "config.json"
+	maxSnapshots       = 200
+	snapshotTimeFormat = "20060102-150405.000"
+
+	comparePrevious = "Previous snapshot"
+	compareCurrent  = "Current config"
+)
+
+// snapshot is a config saved to the daemon at some point.
+type snapshot struct {
+	id   string
+	time time.Time
+}
+
+// historyStore keeps a snapshot of the config every time it is saved.
+type historyStore interface {
+	save(c *api.Config) error
+	// list returns the snapshots, newest first.
+	list() ([]snapshot, error)
+	load(id string) (*api.Config, error)
+}
+
+// openHistory returns the snapshot store, which is a local git repository if
+// git is set and git is installed, or a directory of JSON files otherwise.
+func openHistory(git bool) (historyStore, error) {
+	path, err := configFile(historyDir)
+	if err != nil {
+		return nil, err
+	}
+	if git {
+		store, err := newGitHistory(filepath.Join(path, "git"))
+		if err == nil {
+			return store, nil
+		}
+		logWarning(categoryUI, "Unable to keep history in git, using plain files: "+err.Error())
+	}
+	dir := filepath.Join(path, "snapshots")
+	return &fileHistory{dir: dir}, os.MkdirAll(dir, 0700)
+}
+
+// fileHistory stores each snapshot as a JSON file named after its time,
+// keeping the newest maxSnapshots.
+type fileHistory struct {
+	dir string
+}
+
+func (h *fileHistory) save(c *api.Config) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	snapshots, err := h.list()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 {
+		latest, err := os.ReadFile(filepath.Join(h.dir, snapshots[0].id+".json"))
+		if err == nil && bytes.Equal(latest, data) {
+			return nil
+		}
+	}
+	err = writeJSONFile(filepath.Join(h.dir, time.Now().Format(snapshotTimeFormat)+".json"), c)
+	if err != nil {
+		return err
+	}
+	for i := maxSnapshots - 1; i < len(snapshots); i++ {
+		os.Remove(filepath.Join(h.dir, snapshots[i].id+".json"))
+	}
+	return nil
+}
+
+func (h *fileHistory) list() ([]snapshot, error) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []snapshot
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		t, err := time.ParseInLocation(snapshotTimeFormat, id, time.Local)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{id: id, time: t})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].time.After(snapshots[j].time)
+	})
+	return snapshots, nil
+}
+
+func (h *fileHistory) load(id string) (*api.Config, error) {
+	c := &api.Config{}
+	return c, readJSONFile(filepath.Join(h.dir, id+".json"), c)
+}
+
+// gitHistory commits each snapshot to a local git repository, so the
+// history can also be browsed and shared with git itself.
+type gitHistory struct {
+	dir string
+}
+
+func newGitHistory(dir string) (*gitHistory, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, err
+	}
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	h := &gitHistory{dir: dir}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		_, err = h.git("init", "-q")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *gitHistory) git(args ...string) ([]byte, error) {
+	args = append([]string{"-C", h.dir, "-c", "user.name=" + appDirName, "-c", "user.email=" + appDirName + "@localhost"}, args...)
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = errors.New(strings.TrimSpace(stderr.String()))
+	}
+	return out, err
+}
+
+func (h *gitHistory) save(c *api.Config) error {
+	err := writeJSONFile(filepath.Join(h.dir, historyConfigFile), c)
+	if err != nil {
+		return err
+	}
+	_, err = h.git("add", historyConfigFile)
+	if err != nil {
+		return err
+	}
+	if _, err = h.git("diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	_, err = h.git("commit", "-q", "-m", "Save "+time.Now().Format("2006-01-02 15:04:05"))
+	return err
+}
+
+func (h *gitHistory) list() ([]snapshot, error) {
+	out, err := h.git("log", "--format=%H %ct", "--", historyConfigFile)
+	if err != nil {
+		// a repository without commits has no history yet
+		return nil, nil
+	}
+	var snapshots []snapshot
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{id: fields[0], time: time.Unix(seconds, 0)})
+	}
+	return snapshots, nil
+}
+
+func (h *gitHistory) load(id string) (*api.Config, error) {
+	out, err := h.git("show", id+":"+historyConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	c := &api.Config{}
+	return c, json.Unmarshal(out, c)
+}
+
+// saveSnapshot records the config just committed to the daemon.
+func (e *editor) saveSnapshot() {
+	if e.history == nil {
+		return
+	}
+	err := e.history.save(e.config)
+	if err != nil {
+		logError(categoryUI, "Unable to save config history", err)
+	}
+}
+
+// cloneConfig deep copies a config.
+func cloneConfig(c *api.Config) (*api.Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var clone api.Config
+	return &clone, json.Unmarshal(data, &clone)
+}
+
+func findDeck(c *api.Config, serial string) *api.Deck {
+	for i := range c.Decks {
+		if c.Decks[i].Serial == serial {
+			return &c.Decks[i]
+		}
+	}
+	return nil
+}
+
+func keyFields(key api.Key) map[string]string {
+	data, _ := json.Marshal(key)
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	fields := make(map[string]string)
+	for name, value := range raw {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for field, fieldValue := range v {
+				fields[name+"."+field] = fmt.Sprint(fieldValue)
+			}
+		default:
+			fields[name] = fmt.Sprint(v)
+		}
+	}
+	return fields
+}
+
+// keyDiff lists the fields that differ between two versions of a key.
+func keyDiff(old, new api.Key) []string {
+	before, after := keyFields(old), keyFields(new)
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var changes []string
+	for _, name := range names {
+		if before[name] != after[name] {
+			changes = append(changes, fmt.Sprintf("%s: %q → %q", name, before[name], after[name]))
+		}
+	}
+	return changes
+}
+
+// configDiff describes how new differs from old, key by key.
+func (e *editor) configDiff(old, new *api.Config) []string {
+	var serials []string
+	seen := make(map[string]bool)
+	for _, c := range []*api.Config{old, new} {
+		for _, deck := range c.Decks {
+			if !seen[deck.Serial] {
+				seen[deck.Serial] = true
+				serials = append(serials, deck.Serial)
+			}
+		}
+	}
+
+	var lines []string
+	for _, serial := range serials {
+		before, after := findDeck(old, serial), findDeck(new, serial)
+		if before == nil {
+			lines = append(lines, fmt.Sprintf(lang.L("%s added with %d pages"), serial, len(after.Pages)))
+			continue
+		}
+		if after == nil {
+			lines = append(lines, fmt.Sprintf(lang.L("%s removed"), serial))
+			continue
+		}
+		for p := 0; p < len(before.Pages) || p < len(after.Pages); p++ {
+			if p >= len(before.Pages) {
+				lines = append(lines, fmt.Sprintf(lang.L("%s, page %d added"), serial, p+1))
+				continue
+			}
+			if p >= len(after.Pages) {
+				lines = append(lines, fmt.Sprintf(lang.L("%s, page %d removed"), serial, p+1))
+				continue
+			}
+			for i := 0; i < len(before.Pages[p]) || i < len(after.Pages[p]); i++ {
+				var a, b api.Key
+				if i < len(before.Pages[p]) {
+					a = before.Pages[p][i]
+				}
+				if i < len(after.Pages[p]) {
+					b = after.Pages[p][i]
+				}
+				changes := keyDiff(a, b)
+				if len(changes) > 0 {
+					lines = append(lines, e.describeLocation(serial, p, i)+"\n    "+strings.Join(changes, "\n    "))
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// restoreConfig replaces the whole config with a snapshot. Undo only knows
+// key changes, which no longer apply once pages are replaced, so restoring
+// empties it.
+func (e *editor) restoreConfig(c *api.Config) {
+	restored, err := cloneConfig(c)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	e.config = restored
+	e.undoStack = nil
+	e.ensureDecks()
+	e.pagesChanged(e.currentDevice.Page)
+}
+
+// restoreDeck replaces the pages of one deck with those in a snapshot,
+// emptying undo as restoreConfig does.
+func (e *editor) restoreDeck(c *api.Config, serial string) {
+	deck := findDeck(c, serial)
+	current := e.deckConfig(serial)
+	info := e.deviceInfo(serial)
+	if deck == nil || current == nil || info == nil {
+		dialog.ShowError(fmt.Errorf(lang.L("Device %s is not connected"), serial), e.win)
+		return
+	}
+	current.Pages = nil
+	for _, page := range deck.Pages {
+		current.Pages = append(current.Pages, fitPage(page, info.Cols*info.Rows))
+	}
+	e.undoStack = nil
+	e.ensureDecks()
+	e.pagesChanged(e.currentDevice.Page)
+}
+
+// restorePage puts back one page from a snapshot as an undoable change, or
+// adds it as a new page if the deck has fewer pages now.
+func (e *editor) restorePage(c *api.Config, serial string, page int) {
+	deck := findDeck(c, serial)
+	current := e.deckConfig(serial)
+	info := e.deviceInfo(serial)
+	if deck == nil || current == nil || info == nil || page >= len(deck.Pages) {
+		dialog.ShowError(errors.New(lang.L("Page is not in the snapshot")), e.win)
+		return
+	}
+	if page >= len(current.Pages) {
+		current.Pages = append(current.Pages, fitPage(deck.Pages[page], info.Cols*info.Rows))
+		e.pagesChanged(e.currentDevice.Page)
+		return
+	}
+	var changes []keyChange
+	for i := range current.Pages[page] {
+		var after api.Key
+		if i < len(deck.Pages[page]) {
+			after = deck.Pages[page][i]
+		}
+		if len(keyDiff(current.Pages[page][i], after)) > 0 {
+			changes = append(changes, keyChange{serial: serial, page: page, index: i,
+				before: copyKey(current.Pages[page][i]), after: copyKey(after)})
+		}
+	}
+	e.applyChanges(changes)
+}
+
+// showHistory browses the saved snapshots, shows what changed in each and
+// restores all or part of one.
+func (e *editor) showHistory() {
+	if e.history == nil {
+		dialog.ShowError(errors.New(lang.L("Config history is not available")), e.win)
+		return
+	}
+	snapshots, err := e.history.list()
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+
+	var selected *api.Config
+	selectedIndex := -1
+	diff := widget.NewLabel(lang.L("Select a snapshot"))
+	diff.Wrapping = fyne.TextWrapWord
+	compare := widget.NewSelect(localise([]string{comparePrevious, compareCurrent}), nil)
+	deckSelect := widget.NewSelect(nil, nil)
+	pageSelect := widget.NewSelect(nil, nil)
+
+	showDiff := func() {
+		if selected == nil {
+			return
+		}
+		base := &api.Config{}
+		if compare.Selected == lang.L(compareCurrent) {
+			base = e.config
+		} else if selectedIndex+1 < len(snapshots) {
+			previous, err := e.history.load(snapshots[selectedIndex+1].id)
+			if err != nil {
+				diff.SetText(err.Error())
+				return
+			}
+			base = previous
+		}
+		// against the current config this shows what restoring would change
+		lines := e.configDiff(base, selected)
+		if len(lines) == 0 {
+			diff.SetText(lang.L("No changes"))
+			return
+		}
+		diff.SetText(strings.Join(lines, "\n"))
+	}
+	compare.OnChanged = func(string) {
+		showDiff()
+	}
+	deckSelect.OnChanged = func(serial string) {
+		var pages []string
+		if deck := findDeck(selected, serial); deck != nil {
+			for p := range deck.Pages {
+				pages = append(pages, strconv.Itoa(p+1))
+			}
+		}
+		pageSelect.Options = pages
+		pageSelect.ClearSelected()
+		if len(pages) > 0 {
+			pageSelect.SetSelected(pages[0])
+		}
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(snapshots)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(snapshots[id].time.Format("2006-01-02 15:04:05"))
+		})
+	list.OnSelected = func(id widget.ListItemID) {
+		c, err := e.history.load(snapshots[id].id)
+		if err != nil {
+			dialog.ShowError(err, e.win)
+			return
+		}
+		selected, selectedIndex = c, id
+		var serials []string
+		for _, deck := range c.Decks {
+			serials = append(serials, deck.Serial)
+		}
+		deckSelect.Options = serials
+		deckSelect.ClearSelected()
+		if deck := findDeck(c, e.currentDevice.Serial); deck != nil {
+			deckSelect.SetSelected(deck.Serial)
+		} else if len(serials) > 0 {
+			deckSelect.SetSelected(serials[0])
+		}
+		showDiff()
+	}
+	compare.SetSelected(lang.L(comparePrevious))
+
+	var d dialog.Dialog
+	confirm := func(title, message string, restore func()) {
+		if selected == nil {
+			return
+		}
+		dialog.ShowConfirm(title, message,
+			func(ok bool) {
+				if ok {
+					restore()
+					d.Hide()
+				}
+			}, e.win)
+	}
+	restoreConfig := widget.NewButton(lang.L("Restore Config"), func() {
+		confirm(lang.L("Restore config?"), lang.L("The restored config replaces what is in the editor. Save to keep it."), func() {
+			e.restoreConfig(selected)
+		})
+	})
+	restoreDeck := widget.NewButton(lang.L("Restore Deck"), func() {
+		confirm(lang.L("Restore deck?"), lang.L("The restored deck replaces what is in the editor. Save to keep it."), func() {
+			e.restoreDeck(selected, deckSelect.Selected)
+		})
+	})
+	restorePage := widget.NewButton(lang.L("Restore Page"), func() {
+		page, err := strconv.Atoi(pageSelect.Selected)
+		if err != nil {
+			return
+		}
+		confirm(lang.L("Restore page?"), lang.L("The restored page replaces what is in the editor. Save to keep it."), func() {
+			e.restorePage(selected, deckSelect.Selected, page-1)
+		})
+	})
+
+	git := widget.NewCheck(lang.L("Keep history in a git repository"), func(checked bool) {
+		if checked == e.settings.GitHistory {
+			return
+		}
+		e.settings.GitHistory = checked
+		e.saveSettings()
+		store, err := openHistory(checked)
+		if err != nil {
+			dialog.ShowError(err, e.win)
+			return
+		}
+		e.history = store
+		d.Hide()
+		e.showHistory()
+	})
+	git.SetChecked(e.settings.GitHistory)
+
+	controls := container.NewVBox(
+		widget.NewForm(widget.NewFormItem(lang.L("Compare with"), compare)),
+		container.NewHBox(restoreConfig, layout.NewSpacer(), widget.NewLabel(lang.L("Deck")), deckSelect, restoreDeck,
+			widget.NewLabel(lang.L("Page")), pageSelect, restorePage),
+		git,
+	)
+	split := container.NewHSplit(list, container.NewVScroll(diff))
+	split.Offset = 0.25
+	content := container.NewBorder(nil, controls, nil, nil, split)
+	d = dialog.NewCustom(lang.L("History"), lang.L("Close"), content, e.win)
+	d.Resize(fyne.NewSize(1000, 600))
+	d.Show()
+	if len(snapshots) > 0 {
+		list.Select(0)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func testHistory(t *testing.T, store historyStore) {
+	t.Helper()
+	first := &api.Config{Decks: []api.Deck{{Serial: "A", Pages: []api.Page{{{Text: "one"}}}}}}
+	second := &api.Config{Decks: []api.Deck{{Serial: "A", Pages: []api.Page{{{Text: "two"}}}}}}
+	for _, c := range []*api.Config{first, first, second} {
+		err := store.save(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	snapshots, err := store.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2 as the repeated save changed nothing", len(snapshots))
+	}
+	latest, err := store.load(snapshots[0].id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Decks[0].Pages[0][0].Text != "two" {
+		t.Errorf("newest snapshot has %q, want two", latest.Decks[0].Pages[0][0].Text)
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	testHistory(t, &fileHistory{dir: t.TempDir()})
+}
+
+func TestGitHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	store, err := newGitHistory(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHistory(t, store)
+}
+
+func TestSaveSnapshotsAndRestorePage(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "original"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	e.saveConfig()
+	time.Sleep(5 * time.Millisecond)
+	b := e.buttons[0].(*button)
+	b.key.Text = "edited"
+	b.updateKey()
+	e.saveConfig()
+
+	snapshots, err := e.history.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots after two saves, want 2", len(snapshots))
+	}
+	old, err := e.history.load(snapshots[1].id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := strings.Join(e.configDiff(old, e.config), "\n")
+	if !strings.Contains(diff, `text: "original" → "edited"`) {
+		t.Errorf("diff does not show the edit:\n%s", diff)
+	}
+
+	e.restorePage(old, "A", 0)
+	if text := e.deckConfig("A").Pages[0][0].Text; text != "original" {
+		t.Errorf("key after restoring the page is %q, want original", text)
+	}
+	e.undo()
+	if text := e.deckConfig("A").Pages[0][0].Text; text != "edited" {
+		t.Errorf("key after undoing the restore is %q, want edited", text)
+	}
+
+	e.restorePage(old, "A", 0)
+	e.restoreDeck(old, "A")
+	e.undo()
+	if text := e.deckConfig("A").Pages[0][0].Text; text != "original" {
+		t.Errorf("undo after restoring the deck went back to %q, want nothing to undo", text)
+	}
+	e.restorePage(old, "A", 0)
+	e.restoreConfig(old)
+	if len(e.undoStack) != 0 {
+		t.Errorf("%d steps left to undo after restoring the config, want none", len(e.undoStack))
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"github.com/unix-streamdeck/api"
+)
+
+const importedIconsDir = "imported"
+
+// importedProfile is a set of pages read from another Stream Deck app, with
+// SwitchPage numbers relative to its own first page.
+type importedProfile struct {
+	name     string
+	pages    []api.Page
+	unmapped []string
+	icons    []importedIcon
+}
+
+// importedIcon is an image of a key read from a profile. It is only written
+// to the config directory once the import is confirmed.
+type importedIcon struct {
+	page, index int
+	ext         string
+	data        []byte
+}
+
+func (p *importedProfile) report(page, index int, format string, args ...interface{}) {
+	p.unmapped = append(p.unmapped, fmt.Sprintf(lang.L("Page %d, key %d: %s"), page+1, index+1, fmt.Sprintf(lang.L(format), args...)))
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// importIconDir creates a directory for the images of an import of profile,
+// so the keys keep working after the original file is gone. Each import gets
+// a new one, leaving the images of earlier imports of the profile alone.
+func importIconDir(profile string) (string, error) {
+	path, err := configFile(importedIconsDir)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(path, 0700)
+	if err != nil {
+		return "", err
+	}
+	name := strings.Trim(unsafeName.ReplaceAllString(profile, "_"), "_")
+	if name == "" {
+		name = "profile"
+	}
+	return os.MkdirTemp(path, name+"-")
+}
+
+// saveIcons writes the images of p to a directory of their own and points
+// their keys at them.
+func (p *importedProfile) saveIcons() error {
+	if len(p.icons) == 0 {
+		return nil
+	}
+	dir, err := importIconDir(p.name)
+	if err != nil {
+		return err
+	}
+	for _, icon := range p.icons {
+		target := filepath.Join(dir, fmt.Sprintf("page%d-key%d%s", icon.page+1, icon.index+1, icon.ext))
+		err = os.WriteFile(target, icon.data, 0600)
+		if err != nil {
+			return err
+		}
+		p.pages[icon.page][icon.index].Icon = target
+	}
+	p.icons = nil
+	return nil
+}
+
+const (
+	importSkip    = "Skip"
+	importAppend  = "Add after existing pages"
+	importReplace = "Replace existing pages"
+
+	previewIconSize = 48
+)
+
+// showImport picks a profile exported by another Stream Deck app and offers
+// to merge its pages into the loaded config.
+func (e *editor) showImport() {
+	file, err := zenity.SelectFile(zenity.FileFilters{
+		zenity.FileFilter{Name: "Stream Deck profiles", Patterns: []string{"*.streamDeckProfile", "*.json"}},
+	})
+	if err != nil && err.Error() != "dialog canceled" {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	if file == "" {
+		return
+	}
+	if strings.HasSuffix(strings.ToLower(file), ".json") {
+		decks, err := readPythonUIConfig(file)
+		if err != nil {
+			dialog.ShowError(err, e.win)
+			return
+		}
+		e.previewDeckImport(filepath.Base(file), decks)
+		return
+	}
+	profile, err := readElgatoProfile(file, e.currentDevice.Cols, e.currentDevice.Rows)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	e.confirmImport(profile)
+}
+
+func countKeys(pages []api.Page) int {
+	keys := 0
+	for _, page := range pages {
+		for _, key := range page {
+			if !keyEmpty(key) {
+				keys++
+			}
+		}
+	}
+	return keys
+}
+
+func unmappedReport(content *fyne.Container, unmapped []string) {
+	if len(unmapped) == 0 {
+		return
+	}
+	scroll := container.NewVScroll(widget.NewLabel(strings.Join(unmapped, "\n")))
+	scroll.SetMinSize(fyne.NewSize(500, 150))
+	content.Add(widget.NewLabel(lang.L("These actions could not be mapped and were left empty or partly set up:")))
+	content.Add(scroll)
+}
+
+// confirmImport summarises profile, lists what could not be mapped and adds
+// the pages after the deck's existing ones once the user agrees.
+func (e *editor) confirmImport(profile *importedProfile) {
+	summary := widget.NewLabel(fmt.Sprintf(lang.L("%d pages with %d keys will be added after page %d."),
+		len(profile.pages), countKeys(profile.pages), len(e.currentDeviceConfig.Pages)))
+	content := container.NewVBox(summary)
+	unmappedReport(content, profile.unmapped)
+	dialog.ShowCustomConfirm(lang.L("Import")+" "+profile.name, lang.L("Import"), lang.L("Cancel"), content, func(ok bool) {
+		if !ok {
+			return
+		}
+		err := profile.saveIcons()
+		if err != nil {
+			dialog.ShowError(err, e.win)
+			return
+		}
+		e.importPages(profile.pages)
+	}, e.win)
+}
+
+// previewDeckImport shows the pages read for each deck of an imported config,
+// lets the user pick the connected deck each goes to and merges them.
+func (e *editor) previewDeckImport(name string, decks map[string]*importedProfile) {
+	serials := sortedSerials(decks)
+	if len(serials) == 0 {
+		dialog.ShowInformation(lang.L("Import")+" "+name, lang.L("The file has no pages to import."), e.win)
+		return
+	}
+
+	targetNames := []string{lang.L(importSkip)}
+	for _, info := range e.info {
+		targetNames = append(targetNames, deviceLabel(info))
+	}
+	targets := make(map[string]*widget.Select)
+	form := widget.NewForm()
+	dropped, setDropped := droppedKeysLabel()
+	updateDropped := func() {
+		cut := 0
+		for serial, target := range targets {
+			if info := e.deviceInfo(target.Selected[strings.LastIndex(target.Selected, " ")+1:]); info != nil {
+				cut += cutKeys(decks[serial].pages, info.Cols*info.Rows)
+			}
+		}
+		setDropped(cut)
+	}
+	var unmapped []string
+	for _, serial := range serials {
+		profile := decks[serial]
+		target := widget.NewSelect(targetNames, nil)
+		info := e.deviceInfo(serial)
+		if info == nil && len(serials) == 1 {
+			info = e.currentDevice
+		}
+		if info != nil {
+			target.SetSelected(deviceLabel(info))
+		} else {
+			target.SetSelected(targetNames[0])
+		}
+		targets[serial] = target
+		target.OnChanged = func(string) { updateDropped() }
+		form.Append(fmt.Sprintf(lang.L("%s (%d pages, %d keys)"), serial, len(profile.pages), countKeys(profile.pages)), target)
+		for _, line := range profile.unmapped {
+			unmapped = append(unmapped, serial+": "+line)
+		}
+	}
+	updateDropped()
+	mode := widget.NewRadioGroup(localise([]string{importAppend, importReplace}), nil)
+	mode.SetSelected(lang.L(importAppend))
+
+	preview := container.NewCenter()
+	var previewDeck *importedProfile
+	pageSelect := widget.NewSelect(nil, func(selected string) {
+		page, err := strconv.Atoi(selected)
+		if err != nil || previewDeck == nil || page < 1 || page > len(previewDeck.pages) {
+			return
+		}
+		cols := e.currentDevice.Cols
+		if info := e.deviceInfo(previewDeck.name); info != nil {
+			cols = info.Cols
+		}
+		preview.Objects = []fyne.CanvasObject{pagePreview(previewDeck.pages[page-1], cols)}
+		preview.Refresh()
+	})
+	deckSelect := widget.NewSelect(serials, func(serial string) {
+		previewDeck = decks[serial]
+		var pages []string
+		for p := range previewDeck.pages {
+			pages = append(pages, strconv.Itoa(p+1))
+		}
+		pageSelect.Options = pages
+		pageSelect.SetSelected(pages[0])
+	})
+	deckSelect.SetSelected(serials[0])
+
+	content := container.NewVBox(form, dropped, mode, widget.NewSeparator(),
+		container.NewHBox(widget.NewLabel(lang.L("Preview")), deckSelect, widget.NewLabel(lang.L("Page")), pageSelect), preview)
+	unmappedReport(content, unmapped)
+	dialog.ShowCustomConfirm(lang.L("Import")+" "+name, lang.L("Import"), lang.L("Cancel"), container.NewVScroll(content), func(ok bool) {
+		if !ok {
+			return
+		}
+		replaced := make(map[string]bool)
+		for _, serial := range serials {
+			selected := targets[serial].Selected
+			if selected == targetNames[0] || selected == "" {
+				continue
+			}
+			target := selected[strings.LastIndex(selected, " ")+1:]
+			replace := mode.Selected == lang.L(importReplace) && !replaced[target]
+			replaced[target] = true
+			e.mergePages(target, decks[serial].pages, replace)
+		}
+		e.pagesChanged(e.currentDevice.Page)
+	}, e.win)
+}
+
+// pagePreview draws the keys of page small, in a grid of cols columns.
+func pagePreview(page api.Page, cols int) fyne.CanvasObject {
+	var cells []fyne.CanvasObject
+	for _, key := range page {
+		cell := container.NewStack(canvas.NewRectangle(color.Black))
+		if key.Icon != "" {
+			img, err := iconImage(key.Icon, previewIconSize)
+			if err == nil {
+				icon := canvas.NewImageFromImage(img)
+				icon.FillMode = canvas.ImageFillContain
+				cell.Add(icon)
+			}
+		}
+		if key.Text != "" {
+			img, err := textImage(key.Text, key.TextSize, key.TextAlignment, previewIconSize)
+			if err == nil {
+				text := canvas.NewImageFromImage(img)
+				text.FillMode = canvas.ImageFillContain
+				cell.Add(text)
+			}
+		}
+		size := canvas.NewRectangle(color.Transparent)
+		size.SetMinSize(fyne.NewSize(previewIconSize, previewIconSize))
+		cell.Add(size)
+		cells = append(cells, cell)
+	}
+	return container.NewGridWithColumns(cols, cells...)
+}
+
+// importPages appends pages to the current deck.
+func (e *editor) importPages(pages []api.Page) {
+	offset := e.mergePages(e.currentDevice.Serial, pages, false)
+	e.pagesChanged(offset)
+}
+
+// mergePages adds pages after the existing pages of the deck of serial, or
+// in place of them, shifting their SwitchPage numbers to match. It returns
+// the index of the first merged page; the caller pushes the config.
+func (e *editor) mergePages(serial string, pages []api.Page, replace bool) int {
+	deck := e.deckConfig(serial)
+	info := e.deviceInfo(serial)
+	if deck == nil || info == nil {
+		return 0
+	}
+	if replace {
+		deck.Pages = nil
+		e.forgetUndo(serial)
+	}
+	offset := len(deck.Pages)
+	imported := &api.Deck{Pages: pages}
+	remapSwitchPages(imported, func(page int) int {
+		return page + offset
+	})
+	for _, page := range imported.Pages {
+		deck.Pages = append(deck.Pages, fitPage(page, info.Cols*info.Rows))
+	}
+	applyNavigation(deck, e.settings.deck(serial).Navigation)
+	syncFolders(deck, e.settings.deck(serial).Navigation.reserved())
+	return offset
+}
+
+func keyEmpty(key api.Key) bool {
+	return key.Text == "" && key.Icon == "" && key.Command == "" && key.Keybind == "" && key.Url == "" &&
+		key.SwitchPage == 0 && key.Brightness == 0 && key.IconHandler == "" && key.KeyHandler == ""
+}
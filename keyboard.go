@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+	"github.com/unix-streamdeck/api"
+)
+
+// describeKey says in words where a key is and what it shows and does, for
+// the status line. Fyne has no accessibility API, so screen readers do not
+// get it.
+func describeKey(info *api.StreamDeckInfo, page, pages, index int, text, icon string, key api.Key) string {
+	var parts []string
+	if text != "" {
+		parts = append(parts, fmt.Sprintf("%q", strings.ReplaceAll(text, "\n", " ")))
+	}
+	if icon != "" {
+		parts = append(parts, fmt.Sprintf(lang.L("icon %s"), filepath.Base(icon)))
+	}
+	if key.IconHandler != "" && key.IconHandler != "Default" {
+		parts = append(parts, fmt.Sprintf(lang.L("%s icon"), key.IconHandler))
+	}
+
+	switch {
+	case key.KeyHandlerFields[macroField] != "":
+		steps, _ := parseMacro(key.KeyHandlerFields)
+		parts = append(parts, fmt.Sprintf(lang.L("runs a macro of %d steps"), len(steps)))
+	case key.Command != "":
+		parts = append(parts, fmt.Sprintf(lang.L("runs %s"), key.Command))
+	}
+	if key.Keybind != "" {
+		parts = append(parts, fmt.Sprintf(lang.L("presses %s"), key.Keybind))
+	}
+	if key.Url != "" {
+		parts = append(parts, fmt.Sprintf(lang.L("opens %s"), key.Url))
+	}
+	switch {
+	case isFolderBackKey(key):
+		parts = append(parts, fmt.Sprintf(lang.L("goes back to page %d"), key.SwitchPage))
+	case isFolderKey(key):
+		parts = append(parts, fmt.Sprintf(lang.L("opens the folder on page %d"), key.SwitchPage))
+	case key.SwitchPage > 0:
+		parts = append(parts, fmt.Sprintf(lang.L("switches to page %d"), key.SwitchPage))
+	}
+	if key.Brightness > 0 {
+		parts = append(parts, fmt.Sprintf(lang.L("sets brightness to %d%%"), key.Brightness))
+	}
+	if key.KeyHandler != "" && key.KeyHandler != "Default" {
+		parts = append(parts, fmt.Sprintf(lang.L("handled by %s"), key.KeyHandler))
+	}
+	if gestureBound(key, gestureLongPress) {
+		parts = append(parts, lang.L("has a long press action the daemon does not run yet"))
+	}
+	if gestureBound(key, gestureDoublePress) {
+		parts = append(parts, lang.L("has a double press action the daemon does not run yet"))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, lang.L("empty"))
+	}
+
+	return fmt.Sprintf(lang.L("%s, page %d of %d, row %d, column %d: %s"), info.Serial, page+1, pages,
+		index/info.Cols+1, index%info.Cols+1, strings.Join(parts, ", "))
+}
+
+// accessibleLabel describes the key shown on b.
+func (b *button) accessibleLabel() string {
+	pages := 0
+	if deck := b.editor.deckConfig(b.device.Serial); deck != nil {
+		pages = len(deck.Pages)
+	}
+	text, icon := b.face()
+	return describeKey(b.device, b.device.Page, pages, b.keyID, text, icon, b.key)
+}
+
+// announce shows the description of b in the status line.
+func (e *editor) announce(b *button) {
+	if e.status != nil {
+		e.status.SetText(b.accessibleLabel())
+	}
+}
+
+// focusButton makes b the key being edited and gives it keyboard focus.
+func (e *editor) focusButton(b *button) {
+	e.editButton(b)
+	e.win.Canvas().Focus(b)
+}
+
+// moveFocus moves the keyboard focus from b by rows and cols, stopping at the
+// edges of the deck.
+func (e *editor) moveFocus(b *button, rows, cols int) {
+	info := b.device
+	row := clamp(b.keyID/info.Cols+rows, 0, info.Rows-1)
+	col := clamp(b.keyID%info.Cols+cols, 0, info.Cols-1)
+	buttons := e.deviceButtons[info.Serial]
+	if index := row*info.Cols + col; index < len(buttons) {
+		e.focusButton(buttons[index].(*button))
+	}
+}
+
+// openEditor edits b and moves the focus to the handler of the open tab.
+func (e *editor) openEditor(b *button) {
+	e.editButton(b)
+	if e.tabs.SelectedIndex() == 0 {
+		e.win.Canvas().Focus(e.iconHandler)
+	} else {
+		e.win.Canvas().Focus(e.keyHandler)
+	}
+}
+
+// clearKey empties the selected keys, or b alone, as one undo step.
+func (e *editor) clearKey(b *button) {
+	if len(e.selection) > 1 {
+		e.clearSelected()
+		return
+	}
+	e.applyChanges([]keyChange{{serial: b.device.Serial, page: b.device.Page, index: b.keyID,
+		before: copyKey(b.key), after: api.Key{}}})
+	e.refreshEditor()
+	e.announce(b)
+}
+
+// pageFocus shows another page of the deck of b, keeping the focus on the
+// same position.
+func (e *editor) pageFocus(b *button, delta int) {
+	e.setDevicePage(b.device, b.device.Page+delta)
+	e.focusButton(b)
+}
+
+func (b *button) FocusGained() {
+	b.focused = true
+	b.Refresh()
+	b.editor.announce(b)
+}
+
+func (b *button) FocusLost() {
+	b.focused = false
+	b.Refresh()
+}
+
+func (b *button) TypedRune(r rune) {
+}
+
+func (b *button) TypedKey(ev *fyne.KeyEvent) {
+	e := b.editor
+	switch ev.Name {
+	case fyne.KeyUp:
+		e.moveFocus(b, -1, 0)
+	case fyne.KeyDown:
+		e.moveFocus(b, 1, 0)
+	case fyne.KeyLeft:
+		e.moveFocus(b, 0, -1)
+	case fyne.KeyRight:
+		e.moveFocus(b, 0, 1)
+	case fyne.KeyReturn, fyne.KeyEnter:
+		e.openEditor(b)
+	case fyne.KeyPageUp:
+		e.pageFocus(b, -1)
+	case fyne.KeyPageDown:
+		e.pageFocus(b, 1)
+	case fyne.KeyDelete, fyne.KeyBackspace:
+		e.clearKey(b)
+	}
+}
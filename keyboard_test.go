@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestArrowKeysMoveFocus(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "one"}, {Text: "two"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	first := e.buttons[0].(*button)
+	e.focusButton(first)
+	first.TypedKey(&fyne.KeyEvent{Name: fyne.KeyRight})
+	second := e.buttons[1].(*button)
+	if e.currentButton != second || e.win.Canvas().Focused() != second {
+		t.Fatal("right arrow did not move the focus to the next key")
+	}
+	if !strings.Contains(e.status.Text, `row 1, column 2: "two"`) {
+		t.Errorf("status reads %q", e.status.Text)
+	}
+
+	second.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	second.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	e.currentButton.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	if e.currentButton != e.buttons[11] {
+		t.Errorf("down arrow past the bottom row focused key %d, want 12", e.currentButton.keyID+1)
+	}
+}
+
+func TestArrowKeysAfterClick(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "one"}, {Text: "two"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	first := e.buttons[0].(*button)
+	test.Tap(first)
+	focused := e.win.Canvas().Focused()
+	if focused != first {
+		t.Fatal("clicking a key did not give it the keyboard focus")
+	}
+	focused.TypedKey(&fyne.KeyEvent{Name: fyne.KeyRight})
+	if e.currentButton != e.buttons[1] {
+		t.Errorf("right arrow after a click edits key %d, want 2", e.currentButton.keyID+1)
+	}
+}
+
+func TestKeyboardEditing(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "one"}}, api.Page{{Text: "other"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	b := e.buttons[0].(*button)
+	e.focusButton(b)
+
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDelete})
+	if key := e.deckConfig("A").Pages[0][0]; !keyEmpty(key) || !strings.HasSuffix(e.status.Text, ": empty") {
+		t.Errorf("delete left %+v, status %q", key, e.status.Text)
+	}
+	e.undo()
+	if e.deckConfig("A").Pages[0][0].Text != "one" {
+		t.Error("undo did not bring back the deleted key")
+	}
+
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyPageDown})
+	if e.currentDevice.Page != 1 || e.currentButton.key.Text != "other" || e.win.Canvas().Focused() != b {
+		t.Errorf("page down shows page %d with %q focused", e.currentDevice.Page+1, e.currentButton.key.Text)
+	}
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyPageUp})
+	if e.currentDevice.Page != 0 {
+		t.Errorf("page up shows page %d, want 1", e.currentDevice.Page+1)
+	}
+
+	b.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if e.win.Canvas().Focused() != e.iconHandler {
+		t.Error("enter did not move the focus to the editor")
+	}
+}
+
+func TestDescribeKey(t *testing.T) {
+	info := &api.StreamDeckInfo{Cols: 5, Rows: 3, Serial: "A"}
+	key := api.Key{Text: "Term", Command: "xterm", SwitchPage: 2,
+		KeyHandlerFields: map[string]string{gestureLongPress + gestureHandler: "Default"}}
+	got := describeKey(info, 0, 2, 6, key.Text, "", key)
+	want := `A, page 1 of 2, row 2, column 2: "Term", runs xterm, switches to page 2, has a long press action the daemon does not run yet`
+	if got != want {
+		t.Errorf("described as\n%s\nwant\n%s", got, want)
+	}
+}
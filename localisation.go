@@ -0,0 +1,154 @@
+package main
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	translationsDir = "translations"
+	systemLanguage  = "System"
+)
+
+//go:embed translations
+var translations embed.FS
+
+// languages are the catalogues shipped with the editor, by the name each
+// language has in itself. English is the source text and needs none.
+var languages = []struct{ code, name string }{
+	{"en", "English"},
+	{"de", "Deutsch"},
+	{"fr", "Français"},
+	{"es", "Español"},
+}
+
+// setupLanguage loads the shipped catalogues and any the user or a module
+// has put in the translations directory of the config directory, named like
+// de.json or counter.de.json. The system locale picks the language unless
+// override names one.
+func setupLanguage(override string) {
+	if override != "" {
+		defer overrideLocale(override)()
+	}
+	err := lang.AddTranslationsFS(translations, translationsDir)
+	if err != nil {
+		logError(categoryUI, "Unable to load translations", err)
+	}
+	dir, err := configFile(translationsDir)
+	if err != nil {
+		return
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			err = lang.AddTranslations(fyne.NewStaticResource(filepath.Base(file), data))
+		}
+		if err != nil {
+			logError(categoryUI, "Unable to load translations from "+file, err)
+		}
+	}
+}
+
+// overrideLocale makes language the first choice of the locale lookup, which
+// reads LANGUAGE unless the locale is unset or C. The lookup only happens as
+// catalogues are added, so restore puts the variables back once they are,
+// keeping the override out of the commands the editor starts.
+func overrideLocale(language string) (restore func()) {
+	var saved []func()
+	setenv := func(env, value string) {
+		old, ok := os.LookupEnv(env)
+		saved = append(saved, func() {
+			if ok {
+				os.Setenv(env, old)
+			} else {
+				os.Unsetenv(env)
+			}
+		})
+		os.Setenv(env, value)
+	}
+	restore = func() {
+		for i := len(saved) - 1; i >= 0; i-- {
+			saved[i]()
+		}
+	}
+
+	setenv("LANGUAGE", language)
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			if value == "C" || value == "POSIX" {
+				setenv(env, language)
+			}
+			return restore
+		}
+	}
+	setenv("LC_MESSAGES", language)
+	return restore
+}
+
+// localise returns the translations of options whose untranslated values the
+// code works with; delocalise maps a shown option back to its value.
+func localise(options []string) []string {
+	shown := make([]string, len(options))
+	for i, option := range options {
+		shown[i] = lang.L(option)
+	}
+	return shown
+}
+
+func delocalise(options []string, shown string) string {
+	for _, option := range options {
+		if lang.L(option) == shown {
+			return option
+		}
+	}
+	return shown
+}
+
+// localiseFields translates the field titles of a module. A catalogue can
+// name a field by module and field name, as module.Counter.mode, or give
+// a translation of the title itself.
+func localiseFields(module string, fields []api.Field) []api.Field {
+	localised := make([]api.Field, len(fields))
+	for i, field := range fields {
+		field.Title = lang.X("module."+module+"."+field.Name, lang.L(field.Title))
+		localised[i] = field
+	}
+	return localised
+}
+
+// showLanguage lets the user choose the language of the editor, which takes
+// effect on the next start.
+func (e *editor) showLanguage() {
+	names := []string{lang.L(systemLanguage)}
+	selected := names[0]
+	for _, l := range languages {
+		names = append(names, l.name)
+		if strings.EqualFold(l.code, e.settings.Language) {
+			selected = l.name
+		}
+	}
+	choice := widget.NewSelect(names, nil)
+	choice.SetSelected(selected)
+
+	dialog.ShowCustomConfirm(lang.L("Language"), lang.L("Apply"), lang.L("Cancel"), widget.NewForm(
+		widget.NewFormItem(lang.L("Language"), choice)), func(ok bool) {
+		if !ok || choice.Selected == selected {
+			return
+		}
+		e.settings.Language = ""
+		if i := choice.SelectedIndex(); i > 0 {
+			e.settings.Language = languages[i-1].code
+		}
+		e.saveSettings()
+		dialog.ShowInformation(lang.L("Language"), lang.L("The language changes when the editor is started again."), e.win)
+	}, e.win)
+}
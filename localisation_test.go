@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/lang"
+	"github.com/unix-streamdeck/api"
+)
+
+// translatableStrings finds the source text of every string the editor
+// translates: the literal arguments of lang.L and localise, the formats of
+// import reports, and the package constants and option lists passed to them.
+func translatableStrings(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	values := make(map[string]ast.Expr)
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+		for _, decl := range f.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok {
+				for _, spec := range gen.Specs {
+					if v, ok := spec.(*ast.ValueSpec); ok {
+						for i, ident := range v.Names {
+							if i < len(v.Values) {
+								values[ident.Name] = v.Values[i]
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var resolve func(expr ast.Expr) []string
+	resolve = func(expr ast.Expr) []string {
+		switch expr := expr.(type) {
+		case *ast.BasicLit:
+			if s, err := strconv.Unquote(expr.Value); err == nil {
+				return []string{s}
+			}
+		case *ast.Ident:
+			if value, ok := values[expr.Name]; ok {
+				return resolve(value)
+			}
+		case *ast.BinaryExpr:
+			left, right := resolve(expr.X), resolve(expr.Y)
+			if len(left) == 1 && len(right) == 1 {
+				return []string{left[0] + right[0]}
+			}
+		case *ast.CompositeLit:
+			var all []string
+			for _, elt := range expr.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.Ident); ok && (key.Name == "Title" || key.Name == "name") {
+						all = append(all, resolve(kv.Value)...)
+					}
+					continue
+				}
+				all = append(all, resolve(elt)...)
+			}
+			return all
+		}
+		return nil
+	}
+
+	found := make(map[string]bool)
+	for _, f := range parsed {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			var arg ast.Expr
+			switch fn := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				if pkg, ok := fn.X.(*ast.Ident); ok && pkg.Name == "lang" && fn.Sel.Name == "L" {
+					arg = call.Args[0]
+				} else if fn.Sel.Name == "report" && len(call.Args) > 2 {
+					arg = call.Args[2]
+				}
+			case *ast.Ident:
+				if fn.Name == "localise" {
+					arg = call.Args[0]
+				} else if fn.Name == "localiseFields" {
+					arg = call.Args[1]
+				}
+			}
+			if arg != nil {
+				for _, s := range resolve(arg) {
+					found[s] = true
+				}
+			}
+			return true
+		})
+	}
+	var all []string
+	for s := range found {
+		all = append(all, s)
+	}
+	sort.Strings(all)
+	return all
+}
+
+func TestCataloguesComplete(t *testing.T) {
+	keys := translatableStrings(t)
+	if len(keys) < 100 {
+		t.Fatalf("found only %d translatable strings", len(keys))
+	}
+	for _, l := range languages[1:] {
+		data, err := translations.ReadFile(translationsDir + "/" + l.code + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		catalogue := make(map[string]string)
+		err = json.Unmarshal(data, &catalogue)
+		if err != nil {
+			t.Fatalf("%s: %v", l.code, err)
+		}
+		for _, key := range keys {
+			if catalogue[key] == "" {
+				t.Errorf("%s has no translation of %q", l.code, key)
+			}
+			if strings.Count(catalogue[key], "%") != strings.Count(key, "%") {
+				t.Errorf("%s translation of %q has other format verbs: %q", l.code, key, catalogue[key])
+			}
+		}
+	}
+}
+
+func TestLanguageOverride(t *testing.T) {
+	// Registered first so it runs once the environment is restored.
+	t.Cleanup(func() {
+		setupLanguage("")
+	})
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANGUAGE"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	err := os.MkdirAll(filepath.Join(dir, appDirName, translationsDir), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, appDirName, translationsDir, "counter.de.json"), []byte(`{"module.Counter.mode": "Modus"}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setupLanguage("")
+	if got := lang.L("Save"); got != "Enregistrer" {
+		t.Errorf("the system locale gives %q for Save, want Enregistrer", got)
+	}
+	setupLanguage("de")
+	if got := lang.L("Save"); got != "Speichern" {
+		t.Errorf("the German override gives %q for Save, want Speichern", got)
+	}
+	for env, want := range map[string]string{"LANGUAGE": "", "LC_MESSAGES": "", "LANG": "fr_FR.UTF-8"} {
+		if got := os.Getenv(env); got != want {
+			t.Errorf("the override left %s=%q for commands the editor starts, want %q", env, got, want)
+		}
+	}
+	fields := localiseFields("Counter", []api.Field{{Title: "Brightness", Name: "level"}, {Title: "Mode", Name: "mode"}})
+	if fields[0].Title != "Helligkeit" || fields[1].Title != "Modus" {
+		t.Errorf("module field titles are %q and %q, want Helligkeit and Modus", fields[0].Title, fields[1].Title)
+	}
+}
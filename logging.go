@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarning
+	levelError
+)
+
+var levelNames = []string{"Debug", "Info", "Warning", "Error"}
+
+func (l logLevel) String() string {
+	return levelNames[l]
+}
+
+const (
+	logFileName  = "streamdeckui.log"
+	maxLogSize   = 1 << 20
+	maxLogFiles  = 3
+	maxLogMemory = 2000
+
+	categoryDaemon = "daemon"
+	categoryPage   = "page"
+	categoryUI     = "ui"
+)
+
+type logEntry struct {
+	time     time.Time
+	level    logLevel
+	category string
+	message  string
+}
+
+func (l logEntry) String() string {
+	return fmt.Sprintf("%s %-7s %-6s %s", l.time.Format("2006-01-02 15:04:05.000"), l.level, l.category, l.message)
+}
+
+// logger keeps recent entries in memory for the diagnostics panel and
+// appends every entry to a log file, rotated once it reaches maxLogSize.
+type logger struct {
+	mu       sync.Mutex
+	entries  []logEntry
+	path     string
+	file     *os.File
+	size     int64
+	listener func()
+}
+
+var logs = &logger{}
+
+// openLogFile starts writing entries to the log file in the user's cache
+// directory. Until it is called, entries are only kept in memory.
+func (l *logger) openLogFile() error {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+	dir = filepath.Join(dir, appDirName)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.path = filepath.Join(dir, logFileName)
+	return l.reopen()
+}
+
+func (l *logger) reopen() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.file, l.size = f, stat.Size()
+	return nil
+}
+
+// rotate moves streamdeckui.log to streamdeckui.log.1 and so on, dropping
+// the oldest, and starts a new file.
+func (l *logger) rotate() error {
+	l.file.Close()
+	l.file = nil
+	for i := maxLogFiles - 1; i > 0; i-- {
+		from := l.path
+		if i > 1 {
+			from = fmt.Sprintf("%s.%d", l.path, i-1)
+		}
+		err := os.Rename(from, fmt.Sprintf("%s.%d", l.path, i))
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, "Unable to rotate log:", err)
+		}
+	}
+	return l.reopen()
+}
+
+func (l *logger) log(level logLevel, category, message string) {
+	entry := logEntry{time: time.Now(), level: level, category: category, message: message}
+
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > maxLogMemory {
+		l.entries = l.entries[len(l.entries)-maxLogMemory:]
+	}
+	if l.file != nil {
+		n, err := fmt.Fprintln(l.file, entry)
+		l.size += int64(n)
+		if err == nil && l.size >= maxLogSize {
+			err = l.rotate()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Unable to write log:", err)
+		}
+	}
+	listener := l.listener
+	l.mu.Unlock()
+
+	if level >= levelWarning {
+		fmt.Fprintln(os.Stderr, entry)
+	}
+	if listener != nil {
+		listener()
+	}
+}
+
+// filter returns the entries at or above level whose text contains query.
+func (l *logger) filter(level logLevel, query string) []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	query = strings.ToLower(query)
+	var entries []logEntry
+	for _, entry := range l.entries {
+		if entry.level >= level && strings.Contains(strings.ToLower(entry.String()), query) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// setListener registers a function called after every new entry.
+func (l *logger) setListener(listener func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listener = listener
+}
+
+func logDebug(category, message string) {
+	logs.log(levelDebug, category, message)
+}
+
+func logInfo(category, message string) {
+	logs.log(levelInfo, category, message)
+}
+
+func logWarning(category, message string) {
+	logs.log(levelWarning, category, message)
+}
+
+func logError(category, message string, err error) {
+	if err != nil {
+		message += ": " + err.Error()
+	}
+	logs.log(levelError, category, message)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	l := &logger{path: filepath.Join(dir, logFileName)}
+	err := l.reopen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		l.file.Close()
+	}()
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(data)
+	}
+	fill := func(message string) {
+		l.size = maxLogSize - 1
+		l.log(levelInfo, categoryUI, message)
+	}
+
+	fill("first")
+	fill("second")
+	if read(logFileName) != "" || !strings.Contains(read(logFileName+".1"), "second") || !strings.Contains(read(logFileName+".2"), "first") {
+		t.Fatalf("after two rotations the logs hold %q, %q and %q", read(logFileName), read(logFileName+".1"), read(logFileName+".2"))
+	}
+	fill("third")
+	if !strings.Contains(read(logFileName+".2"), "second") || !strings.Contains(read(logFileName+".1"), "third") {
+		t.Errorf("the oldest log was not dropped: %q, %q", read(logFileName+".1"), read(logFileName+".2"))
+	}
+
+	// A rotation that cannot move a file says so rather than failing quietly.
+	err = os.Remove(filepath.Join(dir, logFileName+".2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Join(dir, logFileName+".2", "in the way"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.CreateTemp(dir, "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stderr
+	os.Stderr = stderr
+	fill("fourth")
+	os.Stderr = saved
+	stderr.Close()
+	if out := read(filepath.Base(stderr.Name())); !strings.Contains(out, "Unable to rotate log") {
+		t.Errorf("a failed rename was not reported, stderr has %q", out)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	macroField        = editorFieldPrefix + "macro"
+	macroCommandField = editorFieldPrefix + "macro_command"
+
+	stepKeybind = "Keybind"
+	stepCommand = "Command"
+	stepURL     = "URL"
+	stepPage    = "Switch Page"
+	stepType    = "Type Text"
+)
+
+var stepTypes = []string{stepKeybind, stepCommand, stepURL, stepPage, stepType}
+
+// macroStep is one action of a multi-action key, followed by an optional wait.
+type macroStep struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	DelayMs  int    `json:"delay_ms,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// parseMacro reads the steps stored in a key's handler fields.
+func parseMacro(fields map[string]string) ([]macroStep, error) {
+	var steps []macroStep
+	if fields[macroField] == "" {
+		return steps, nil
+	}
+	err := json.Unmarshal([]byte(fields[macroField]), &steps)
+	return steps, err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// stepScript returns the shell command that performs a single step.
+func stepScript(step macroStep, serial string) (string, error) {
+	switch step.Type {
+	case stepKeybind:
+		return "xdotool key -- " + shellQuote(step.Value), nil
+	case stepType:
+		return "xdotool type -- " + shellQuote(step.Value), nil
+	case stepCommand:
+		return "(" + step.Value + ")", nil
+	case stepURL:
+		return "xdg-open " + shellQuote(step.Value), nil
+	case stepPage:
+		page, err := strconv.Atoi(step.Value)
+		if err != nil || page < 1 {
+			return "", errors.New("Invalid page number " + step.Value)
+		}
+		return fmt.Sprintf("dbus-send --session --type=method_call --dest=com.unixstreamdeck.streamdeckd "+
+			"/com/unixstreamdeck/streamdeckd com.unixstreamdeck.streamdeckd.SetPage string:%s int32:%d",
+			shellQuote(serial), page-1), nil
+	}
+	return "", errors.New("Unknown step type " + step.Type)
+}
+
+// compileMacro turns the enabled steps into one shell command, which the
+// daemon runs as the key's Command.
+func compileMacro(steps []macroStep, serial string) (string, error) {
+	var parts []string
+	for _, step := range steps {
+		if step.Disabled {
+			continue
+		}
+		script, err := stepScript(step, serial)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, script)
+		if step.DelayMs > 0 {
+			parts = append(parts, fmt.Sprintf("sleep %.3f", float64(step.DelayMs)/1000))
+		}
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// setMacro stores steps as the macro of key, compiled for the deck of serial.
+// key is left as it was if the steps do not compile.
+func setMacro(key *api.Key, steps []macroStep, serial string) error {
+	command, err := compileMacro(steps, serial)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return err
+	}
+	if key.KeyHandlerFields == nil {
+		key.KeyHandlerFields = make(map[string]string)
+	}
+	key.KeyHandlerFields[macroField] = string(data)
+	key.Command = command
+	return nil
+}
+
+// testStep runs a single step straight away, outside the daemon.
+func (e *editor) testStep(step macroStep) {
+	if step.Type == stepPage {
+		page, err := strconv.Atoi(step.Value)
+		if err != nil || page < 1 || page > len(e.currentDeviceConfig.Pages) {
+			dialog.ShowError(fmt.Errorf(lang.L("Invalid page number %s"), step.Value), e.win)
+			return
+		}
+		e.setPage(page-1, true)
+		return
+	}
+	script, err := stepScript(step, e.currentDevice.Serial)
+	if err == nil {
+		err = exec.Command("/bin/sh", "-c", script).Start()
+	}
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+}
+
+// saveMacro stores steps on the current key and compiles them into its Command.
+func (e *editor) saveMacro(steps []macroStep) error {
+	command, err := compileMacro(steps, e.currentDevice.Serial)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return err
+	}
+	b := e.currentButton
+	if b.key.KeyHandlerFields == nil {
+		b.key.KeyHandlerFields = make(map[string]string)
+	}
+	if b.key.KeyHandlerFields[macroField] == "" && b.key.Command != "" {
+		b.key.KeyHandlerFields[macroCommandField] = b.key.Command
+	}
+	b.key.KeyHandlerFields[macroField] = string(data)
+	b.key.Command = command
+	b.updateKey()
+	e.refreshEditor()
+	return nil
+}
+
+// removeMacro turns the current key back into a single action key, running
+// the command it had before the macro was made.
+func (e *editor) removeMacro() {
+	b := e.currentButton
+	b.key.Command = b.key.KeyHandlerFields[macroCommandField]
+	delete(b.key.KeyHandlerFields, macroField)
+	delete(b.key.KeyHandlerFields, macroCommandField)
+	b.updateKey()
+	e.refreshEditor()
+}
+
+func (e *editor) loadMacroUI() fyne.CanvasObject {
+	fields := e.currentButton.key.KeyHandlerFields
+	if fields[macroField] == "" {
+		create := widget.NewButton(lang.L("Create Macro"), e.showMacroEditor)
+		if fields[toggleField] != "" {
+			create.Disable()
+		}
+		return create
+	}
+	return fyne.NewContainerWithLayout(layout.NewGridLayout(2),
+		widget.NewButton(lang.L("Edit Macro"), e.showMacroEditor),
+		widget.NewButton(lang.L("Remove Macro"), e.removeMacro))
+}
+
+// showMacroEditor edits the ordered steps of the current key's macro.
+func (e *editor) showMacroEditor() {
+	steps, err := parseMacro(e.currentButton.key.KeyHandlerFields)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	if len(steps) == 0 && e.currentButton.key.Command != "" {
+		steps = append(steps, macroStep{Type: stepCommand, Value: e.currentButton.key.Command})
+	}
+
+	list := container.NewVBox()
+	var rebuild func()
+	rebuild = func() {
+		list.Objects = nil
+		for i := range steps {
+			i := i
+			enabled := widget.NewCheck("", func(checked bool) {
+				steps[i].Disabled = !checked
+			})
+			enabled.SetChecked(!steps[i].Disabled)
+			kind := widget.NewSelect(localise(stepTypes), func(value string) {
+				steps[i].Type = delocalise(stepTypes, value)
+			})
+			kind.SetSelected(lang.L(steps[i].Type))
+			value := widget.NewEntry()
+			value.SetText(steps[i].Value)
+			value.OnChanged = func(text string) {
+				steps[i].Value = text
+			}
+			delay := widget.NewEntry()
+			delay.SetPlaceHolder(lang.L("wait ms"))
+			if steps[i].DelayMs > 0 {
+				delay.SetText(strconv.Itoa(steps[i].DelayMs))
+			}
+			delay.Validator = func(text string) error {
+				if text == "" {
+					return nil
+				}
+				_, err := strconv.Atoi(text)
+				return err
+			}
+			delay.OnChanged = func(text string) {
+				if text == "" {
+					steps[i].DelayMs = 0
+				} else if ms, err := strconv.Atoi(text); err == nil {
+					steps[i].DelayMs = ms
+				}
+			}
+			controls := container.NewHBox(
+				widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+					if i > 0 {
+						steps[i-1], steps[i] = steps[i], steps[i-1]
+						rebuild()
+					}
+				}),
+				widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+					if i < len(steps)-1 {
+						steps[i+1], steps[i] = steps[i], steps[i+1]
+						rebuild()
+					}
+				}),
+				widget.NewButtonWithIcon("", theme.MediaPlayIcon(), func() {
+					e.testStep(steps[i])
+				}),
+				widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+					steps = append(steps[:i], steps[i+1:]...)
+					rebuild()
+				}),
+			)
+			left := container.NewHBox(enabled, kind)
+			right := container.NewHBox(delay, controls)
+			list.Add(fyne.NewContainerWithLayout(layout.NewBorderLayout(nil, nil, left, right), left, right, value))
+		}
+		list.Refresh()
+	}
+	rebuild()
+
+	add := widget.NewButtonWithIcon(lang.L("Add Step"), theme.ContentAddIcon(), func() {
+		steps = append(steps, macroStep{Type: stepKeybind})
+		rebuild()
+	})
+	content := fyne.NewContainerWithLayout(layout.NewBorderLayout(nil, add, nil, nil), add, container.NewVScroll(list))
+
+	d := dialog.NewCustomConfirm(lang.L("Macro"), lang.L("Save"), lang.L("Cancel"), content, func(ok bool) {
+		if !ok {
+			return
+		}
+		err := e.saveMacro(steps)
+		if err != nil {
+			dialog.ShowError(err, e.win)
+		}
+	}, e.win)
+	d.Resize(fyne.NewSize(750, 450))
+	d.Show()
+}
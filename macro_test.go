@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestPageChangesRenumberMacros(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{}}, api.Page{{}}, api.Page{{}})
+	var key api.Key
+	setMacro(&key, []macroStep{{Type: stepPage, Value: "2"}, {Type: stepPage, Value: "3"}}, "A")
+	deck.Pages[0][0] = key
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	e.movePage(2, 1)
+	steps, _ := parseMacro(pushedPages(f, "A")[0][0].KeyHandlerFields)
+	if len(steps) != 2 || steps[0].Value != "3" || steps[1].Value != "2" {
+		t.Fatalf("macro steps after moving the third page forward are %+v, want pages 3 and 2", steps)
+	}
+
+	e.removePage(2)
+	key = pushedPages(f, "A")[0][0]
+	steps, _ = parseMacro(key.KeyHandlerFields)
+	if len(steps) != 2 || !steps[0].Disabled || steps[1].Disabled || steps[1].Value != "2" {
+		t.Errorf("macro steps after removing the third page are %+v, want it disabled and page 2 kept", steps)
+	}
+	if want, _ := compileMacro(steps, "A"); key.Command != want {
+		t.Errorf("macro command is %q, want %q", key.Command, want)
+	}
+}
+
+func TestRemoveMacroRestoresCommand(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Command: "echo before"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	test.Tap(e.buttons[0].(*button))
+	err := e.saveMacro([]macroStep{{Type: stepCommand, Value: "echo before"}, {Type: stepKeybind, Value: "Return"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.currentButton.key.Command == "echo before" {
+		t.Fatal("saving the macro did not compile it into the command")
+	}
+	err = e.saveMacro([]macroStep{{Type: stepKeybind, Value: "Return"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.removeMacro()
+	e.saveConfig()
+
+	key := committedPage(t, f, "A", 0)[0]
+	if key.Command != "echo before" || len(key.KeyHandlerFields) != 0 {
+		t.Errorf("key after removing the macro runs %q with fields %v, want echo before and none", key.Command, key.KeyHandlerFields)
+	}
+}
+
+func TestSetMacroReportsBadSteps(t *testing.T) {
+	key := api.Key{Command: "echo kept"}
+	err := setMacro(&key, []macroStep{{Type: stepPage, Value: "none"}}, "A")
+	if err == nil {
+		t.Error("a step to an invalid page compiled")
+	}
+	if key.Command != "echo kept" || key.KeyHandlerFields[macroField] != "" {
+		t.Errorf("a macro that did not compile changed the key to %+v", key)
+	}
+	for _, field := range []string{macroField, macroCommandField, toggleField, gestureLongPress + gestureHandler,
+		gestureDoublePress + gestureHandler, folderField, folderBackField} {
+		if !isEditorField(field) {
+			t.Errorf("editor field %q is not namespaced", field)
+		}
+	}
+}
@@ -1,7 +1,8 @@
 package main
 
 import (
-	"log"
+	"fmt"
+	"os"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -9,25 +10,37 @@ import (
 	"github.com/unix-streamdeck/api"
 )
 
-var conn *api.Connection
+var conn daemon
+
+func fatal(message string, err error) {
+	logError(categoryDaemon, message, err)
+	os.Exit(1)
+}
 
 func main() {
+	err := logs.openLogFile()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to open log file:", err)
+	}
+
 	dev, err := api.Connect()
 	if err != nil {
-		log.Fatal("Could not connect to device: " + err.Error())
+		fatal("Could not connect to device", err)
 	}
-	conn = dev
+	conn = &loggedDaemon{dev}
 
-	defer dev.Close()
-	info, err := dev.GetInfo()
+	defer conn.Close()
+	info, err := conn.GetInfo()
 	if err != nil {
-		log.Fatal("Cound not read device info: " + err.Error())
+		fatal("Could not read device info", err)
 	}
 
 	a := app.New()
 	w := a.NewWindow("StreamDeck Unix")
 
 	e := newEditor(info, w)
+	e.listen()
+	defer e.events.stop()
 
 	// CTRL-S : save config
 	ctrlS := desktop.CustomShortcut{KeyName: fyne.KeyS, Modifier: desktop.ControlModifier}
@@ -45,6 +58,23 @@ func main() {
 		e.pasteButton()
 	})
 
+	// CTRL-F : search all keys
+	ctrlF := desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: desktop.ControlModifier}
+	e.win.Canvas().AddShortcut(&ctrlF, func(shortcut fyne.Shortcut) {
+		e.showSearch()
+	})
+
+	// CTRL-H : find and replace
+	ctrlH := desktop.CustomShortcut{KeyName: fyne.KeyH, Modifier: desktop.ControlModifier}
+	e.win.Canvas().AddShortcut(&ctrlH, func(shortcut fyne.Shortcut) {
+		e.showFindReplace()
+	})
+
+	// CTRL-Z : undo last batch change
+	e.win.Canvas().AddShortcut(&fyne.ShortcutUndo{}, func(shortcut fyne.Shortcut) {
+		e.undo()
+	})
+
 	w.SetContent(e.loadUI())
 	w.ShowAndRun()
 }
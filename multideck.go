@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+// deckView is the button grid of one device, with a header for paging it on
+// its own when every deck is shown side by side.
+type deckView struct {
+	info      *api.StreamDeckInfo
+	name      *widget.Label
+	page      *pageHandle
+	header    *fyne.Container
+	highlight *canvas.Rectangle
+	view      fyne.CanvasObject
+}
+
+func (e *editor) newDeckView(info *api.StreamDeckInfo, buttons []fyne.CanvasObject) *deckView {
+	grid := container.NewGridWithColumns(info.Cols, buttons...)
+	area := newSelectionArea(grid, info.Serial, e)
+	for _, obj := range buttons {
+		obj.(*button).area = area
+	}
+
+	v := &deckView{info: info, name: widget.NewLabel(deviceLabel(info))}
+	v.page = newPageHandle(v, e)
+	v.header = container.NewHBox(v.name, widget.NewButtonWithIcon("", theme.MediaSkipPreviousIcon(), func() {
+		e.setDevicePage(info, info.Page-1)
+	}), v.page, widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), func() {
+		e.setDevicePage(info, info.Page+1)
+	}))
+	v.highlight = canvas.NewRectangle(color.Transparent)
+	v.highlight.StrokeWidth = 2
+	v.view = container.NewStack(v.highlight, container.NewBorder(v.header, nil, nil, nil, area))
+	return v
+}
+
+// showDecks shows every deck when side by side is on, otherwise only the
+// current one.
+func (e *editor) showDecks() {
+	for serial, v := range e.views {
+		current := serial == e.currentDevice.Serial
+		setVisible(v.view, current || e.settings.SideBySide)
+		setVisible(v.header, e.settings.SideBySide)
+		v.name.TextStyle = fyne.TextStyle{Bold: current}
+		v.name.Refresh()
+	}
+	if e.deckRow != nil {
+		e.deckRow.Refresh()
+	}
+}
+
+func (e *editor) toggleSideBySide() {
+	e.settings.SideBySide = !e.settings.SideBySide
+	e.saveSettings()
+	e.showDecks()
+}
+
+// selectDevice makes the deck of serial the one being edited.
+func (e *editor) selectDevice(serial string) {
+	if e.currentDevice.Serial == serial || e.deviceSelector == nil {
+		return
+	}
+	if info := e.deviceInfo(serial); info != nil {
+		e.deviceSelector.SetSelected(deviceLabel(info))
+	}
+}
+
+// setDevicePage shows page on the deck of info, leaving the other decks
+// where they are.
+func (e *editor) setDevicePage(info *api.StreamDeckInfo, page int) {
+	deck := e.deckConfig(info.Serial)
+	if deck == nil || page < 0 || page >= len(deck.Pages) {
+		return
+	}
+	if info == e.currentDevice {
+		e.setPage(page, true)
+		return
+	}
+	err := conn.SetPage(info.Serial, page)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	info.Page = page
+	e.refreshDeck(info)
+}
+
+// keyDrag is a key being dragged onto another key, which may be on another
+// deck. Holding control as the drag starts copies the key instead of swapping
+// the two.
+type keyDrag struct {
+	from, target *button
+	copy         bool
+}
+
+func (e *editor) dragKey(b *button, pos fyne.Position) {
+	if e.drag == nil {
+		e.drag = &keyDrag{from: b, copy: b.modifier&fyne.KeyModifierControl != 0}
+	}
+	target := e.buttonAt(pos)
+	if target == e.drag.target {
+		return
+	}
+	old := e.drag.target
+	e.drag.target = target
+	if old != nil {
+		old.Refresh()
+	}
+	if target != nil {
+		target.Refresh()
+	}
+}
+
+func (e *editor) dropKey() {
+	drag := e.drag
+	e.drag = nil
+	if drag.target == nil {
+		return
+	}
+	drag.target.Refresh()
+	if drag.target != drag.from {
+		e.moveKey(drag.from, drag.target, drag.copy)
+	}
+}
+
+// moveKey puts the key of from on to as one undoable step, and the key of to
+// on from unless copying.
+func (e *editor) moveKey(from, to *button, copying bool) {
+	changes := []keyChange{{serial: to.device.Serial, page: to.device.Page, index: to.keyID,
+		before: copyKey(to.key), after: keyForDeck(from.key, from.device, to.device)}}
+	if !copying {
+		changes = append(changes, keyChange{serial: from.device.Serial, page: from.device.Page, index: from.keyID,
+			before: copyKey(from.key), after: keyForDeck(to.key, to.device, from.device)})
+	}
+	e.applyChanges(changes)
+	for _, info := range []*api.StreamDeckInfo{from.device, to.device} {
+		if deck := e.deckConfig(info.Serial); deck != nil {
+			syncFolders(deck, e.settings.deck(info.Serial).Navigation.reserved())
+		}
+	}
+	err := e.pushConfig()
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+	e.editButton(to)
+}
+
+// keyForDeck returns a copy of key to put on the deck of to. Its page
+// numbers are those of its own deck, so moved elsewhere its page switch,
+// folder and macro page steps are cleared, and its macro is compiled again
+// for the new deck.
+func keyForDeck(key api.Key, from, to *api.StreamDeckInfo) api.Key {
+	key = copyKey(key)
+	if from.Serial == to.Serial {
+		return key
+	}
+	cut := remapKeyPages(&key, func(int) int {
+		return -1
+	}, to.Serial)
+	if cut > 0 {
+		logWarning(categoryUI, fmt.Sprintf("%d page links of a key moved from %s to %s point at pages of %s and were cleared",
+			cut, from.Serial, to.Serial, from.Serial))
+	}
+	return key
+}
+
+// pageLinks counts the links of key to pages: its page switch, the enabled
+// Switch Page steps of its macro and the page switches of its gestures.
+func pageLinks(key api.Key) int {
+	links := 0
+	if key.SwitchPage != 0 {
+		links++
+	}
+	steps, _ := parseMacro(key.KeyHandlerFields)
+	for _, step := range steps {
+		if step.Type == stepPage && !step.Disabled {
+			links++
+		}
+	}
+	for _, gesture := range []string{gestureLongPress, gestureDoublePress} {
+		if key.KeyHandlerFields[gesture+"switch_page"] != "" {
+			links++
+		}
+	}
+	return links
+}
+
+// remapKeyPages rewrites the page links of key with mapping, as
+// remapPageLinks does for a deck, compiling its macro for the deck of
+// serial. A folder key whose page is gone becomes a plain key. It returns
+// the number of links cleared.
+func remapKeyPages(key *api.Key, mapping func(page int) int, serial string) int {
+	before := pageLinks(*key)
+	page := api.Page{*key}
+	remapPageLinks(&api.Deck{Serial: serial, Pages: []api.Page{page}}, mapping)
+	*key = page[0]
+	if isFolderKey(*key) && key.SwitchPage == 0 {
+		delete(key.KeyHandlerFields, folderField)
+	}
+	return before - pageLinks(*key)
+}
+
+// buttonAt returns the key shown at the absolute position pos, if any.
+func (e *editor) buttonAt(pos fyne.Position) *button {
+	for _, info := range e.info {
+		if v := e.views[info.Serial]; v == nil || !v.view.Visible() {
+			continue
+		}
+		for _, obj := range e.deviceButtons[info.Serial] {
+			if contains(obj, pos) {
+				return obj.(*button)
+			}
+		}
+	}
+	return nil
+}
+
+// deckAt returns the deck shown at the absolute position pos, if any.
+func (e *editor) deckAt(pos fyne.Position) *deckView {
+	for _, info := range e.info {
+		if v := e.views[info.Serial]; v != nil && v.view.Visible() && contains(v.view, pos) {
+			return v
+		}
+	}
+	return nil
+}
+
+func contains(obj fyne.CanvasObject, pos fyne.Position) bool {
+	topLeft := fyne.CurrentApp().Driver().AbsolutePositionForObject(obj)
+	size := obj.Size()
+	return pos.X >= topLeft.X && pos.X < topLeft.X+size.Width && pos.Y >= topLeft.Y && pos.Y < topLeft.Y+size.Height
+}
+
+// pageHandle shows the page number of a deck view and can be dragged onto
+// another deck to copy the page there.
+type pageHandle struct {
+	widget.Label
+	view   *deckView
+	editor *editor
+	target *deckView
+}
+
+func newPageHandle(v *deckView, e *editor) *pageHandle {
+	h := &pageHandle{view: v, editor: e}
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+func (h *pageHandle) Dragged(ev *fyne.DragEvent) {
+	target := h.editor.deckAt(ev.AbsolutePosition)
+	if target == h.view {
+		target = nil
+	}
+	if target == h.target {
+		return
+	}
+	if h.target != nil {
+		h.target.highlight.StrokeColor = color.Transparent
+		h.target.highlight.Refresh()
+	}
+	h.target = target
+	if target != nil {
+		target.highlight.StrokeColor = theme.SuccessColor()
+		target.highlight.Refresh()
+	}
+}
+
+func (h *pageHandle) DragEnd() {
+	target := h.target
+	h.target = nil
+	if target == nil {
+		return
+	}
+	target.highlight.StrokeColor = color.Transparent
+	target.highlight.Refresh()
+	h.editor.copyPageToDeck(h.view.info, target.info)
+}
+
+// copyPageToDeck adds the current page of the deck of from after the pages
+// of the deck of to, reflowed if that deck is smaller, and shows it there.
+func (e *editor) copyPageToDeck(from, to *api.StreamDeckInfo) {
+	source := e.deckConfig(from.Serial)
+	target := e.deckConfig(to.Serial)
+	if source == nil || target == nil || from.Page >= len(source.Pages) {
+		return
+	}
+	// Links to the page itself go to the copy; the other pages of the
+	// source deck are not copied, so links to them are cleared.
+	page := make(api.Page, len(source.Pages[from.Page]))
+	cut := 0
+	for i, key := range source.Pages[from.Page] {
+		page[i] = copyKey(key)
+		cut += remapKeyPages(&page[i], func(p int) int {
+			if p == from.Page {
+				return 0
+			}
+			return -1
+		}, from.Serial)
+	}
+	if cut > 0 {
+		logWarning(categoryUI, fmt.Sprintf("%d links to other pages of %s were cleared from the page copied to %s",
+			cut, from.Serial, to.Serial))
+	}
+	offset := len(target.Pages)
+	e.copyPages(from, to, []api.Page{page}, remapReflow, false)
+	err := e.pushConfig()
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	e.setDevicePage(to, offset)
+}
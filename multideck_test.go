@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+// twoDecks returns an editor for an original deck A and a mini B, shown side
+// by side.
+func twoDecks(t *testing.T) (*editor, *fakeDaemon) {
+	infoA, deckA := testDeck("A", api.Page{{Text: "one"}})
+	infoB := &api.StreamDeckInfo{Cols: 3, Rows: 2, IconSize: 72, Serial: "B"}
+	deckB := api.Deck{Serial: "B", Pages: []api.Page{make(api.Page, 6), make(api.Page, 6)}}
+	deckB.Pages[0][2] = api.Key{Text: "three"}
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deckA, deckB}}, infoA, infoB)
+	e, _ := newTestEditor(t, f)
+	e.toggleSideBySide()
+	return e, f
+}
+
+func TestSideBySideEditsEitherDeck(t *testing.T) {
+	e, _ := twoDecks(t)
+	if !e.views["A"].view.Visible() || !e.views["B"].view.Visible() {
+		t.Fatal("side by side shows only one deck")
+	}
+
+	test.Tap(e.deviceButtons["B"][2].(*button))
+	if e.currentDevice.Serial != "B" || e.currentButton.key.Text != "three" {
+		t.Errorf("tapping a key of deck B edits %q on deck %s", e.currentButton.key.Text, e.currentDevice.Serial)
+	}
+
+	e.setDevicePage(e.deviceInfo("A"), 0)
+	e.setDevicePage(e.deviceInfo("B"), 1)
+	if e.deviceInfo("A").Page != 0 || e.views["B"].page.Text != "2/2" {
+		t.Errorf("paging deck B moved deck A to page %d and shows %q", e.deviceInfo("A").Page+1, e.views["B"].page.Text)
+	}
+}
+
+func TestDragKeyBetweenDecks(t *testing.T) {
+	e, f := twoDecks(t)
+	from := e.deviceButtons["A"][0].(*button)
+	to := e.deviceButtons["B"][2].(*button)
+	e.editButton(from)
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(to).Add(fyne.NewPos(5, 5))
+	from.Dragged(&fyne.DragEvent{PointEvent: fyne.PointEvent{AbsolutePosition: pos}})
+	from.DragEnd()
+
+	if key := pushedPages(f, "B")[0][2]; key.Text != "one" {
+		t.Errorf("dropped key on deck B is %q, want one", key.Text)
+	}
+	if key := pushedPages(f, "A")[0][0]; key.Text != "three" {
+		t.Errorf("deck A got %q back, want the swapped three", key.Text)
+	}
+	if e.currentButton != to {
+		t.Error("the dropped key is not the one being edited")
+	}
+
+	e.undo()
+	if e.deckConfig("A").Pages[0][0].Text != "one" || e.deckConfig("B").Pages[0][2].Text != "three" {
+		t.Error("undo did not put both keys back")
+	}
+}
+
+func TestCopyPageToDeck(t *testing.T) {
+	e, f := twoDecks(t)
+	e.copyPageToDeck(e.deviceInfo("A"), e.deviceInfo("B"))
+
+	pages := pushedPages(f, "B")
+	if len(pages) != 3 || pages[2][0].Text != "one" {
+		t.Fatalf("deck B has %d pages after the copy, want the page added as page 3", len(pages))
+	}
+	if e.deviceInfo("B").Page != 2 {
+		t.Errorf("deck B shows page %d, want the copied page", e.deviceInfo("B").Page+1)
+	}
+}
+
+func TestCopyPageToDeckKeepsOnlyItsOwnLinks(t *testing.T) {
+	infoA, deckA := testDeck("A", api.Page{{Text: "first"}}, api.Page{
+		{Text: "self", SwitchPage: 2},
+		{Text: "other", SwitchPage: 1},
+	})
+	setMacro(&deckA.Pages[1][2], []macroStep{{Type: stepPage, Value: "2"}, {Type: stepPage, Value: "1"}}, "A")
+	infoB, deckB := testDeck("B", api.Page{{}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deckA, deckB}}, infoA, infoB)
+	e, _ := newTestEditor(t, f)
+	e.deviceInfo("A").Page = 1
+
+	e.copyPageToDeck(e.deviceInfo("A"), e.deviceInfo("B"))
+
+	pages := pushedPages(f, "B")
+	if len(pages) != 2 {
+		t.Fatalf("deck B has %d pages after the copy, want 2", len(pages))
+	}
+	page := pages[1]
+	if page[0].SwitchPage != 2 || page[1].SwitchPage != 0 {
+		t.Errorf("copied links switch to %d and %d, want the copy and none", page[0].SwitchPage, page[1].SwitchPage)
+	}
+	steps, _ := parseMacro(page[2].KeyHandlerFields)
+	if len(steps) != 2 || steps[0].Value != "2" || steps[0].Disabled || !steps[1].Disabled {
+		t.Errorf("copied macro steps are %+v, want the copy kept and page 1 disabled", steps)
+	}
+}
+
+func TestKeyForDeckClearsPageLinks(t *testing.T) {
+	a := &api.StreamDeckInfo{Serial: "A"}
+	b := &api.StreamDeckInfo{Serial: "B"}
+	key := api.Key{Text: "Apps", SwitchPage: 3, KeyHandlerFields: map[string]string{folderField: "true"}}
+	setMacro(&key, []macroStep{{Type: stepPage, Value: "3"}}, "A")
+
+	if same := keyForDeck(key, a, a); same.SwitchPage != 3 || !isFolderKey(same) {
+		t.Errorf("a key moved on its own deck became %+v", same)
+	}
+	moved := keyForDeck(key, a, b)
+	if moved.SwitchPage != 0 || isFolderKey(moved) {
+		t.Errorf("a folder moved to another deck still opens page %d", moved.SwitchPage)
+	}
+	steps, _ := parseMacro(moved.KeyHandlerFields)
+	if len(steps) != 1 || !steps[0].Disabled {
+		t.Errorf("macro steps moved to another deck are %+v, want the page step disabled", steps)
+	}
+	if want, _ := compileMacro(steps, "B"); moved.Command != want {
+		t.Errorf("moved macro runs %q, want it compiled for deck B", moved.Command)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	navIconSize = 96
+
+	navPrevious = "previous"
+	navNext     = "next"
+	navHome     = "home"
+
+	// navigationField marks the keys generated for navigation, holding the
+	// name of their position.
+	navigationField = editorFieldPrefix + "navigation"
+)
+
+// navIconPath returns the generated icon for a navigation key, drawing it
+// into the user's cache directory the first time it is needed.
+func navIconPath(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, appDirName, "navigation")
+	path := filepath.Join(dir, name+".png")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return path, png.Encode(f, drawNavIcon(name))
+}
+
+// drawNavIcon draws a left or right pointing arrow, or a house for home.
+func drawNavIcon(name string) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, navIconSize, navIconSize))
+	fg := color.NRGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}
+	mid := navIconSize / 2
+	quarter := navIconSize / 4
+	arrow := func(x, y int) bool {
+		dy := abs(y - mid)
+		head := x >= quarter+dy && x < mid
+		shaft := x >= mid && x < navIconSize-quarter && dy < quarter/3
+		return head || shaft
+	}
+	for y := 0; y < navIconSize; y++ {
+		for x := 0; x < navIconSize; x++ {
+			inside := false
+			switch name {
+			case navPrevious, navBack:
+				inside = arrow(x, y)
+			case navNext:
+				inside = arrow(navIconSize-1-x, y)
+			case navHome:
+				roof := y >= quarter && y < mid && abs(x-mid) <= y-quarter
+				walls := y >= mid && y < navIconSize-quarter && abs(x-mid) < quarter*3/4
+				inside = roof || walls
+			}
+			if inside {
+				img.Set(x, y, fg)
+			}
+		}
+	}
+	return img
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// navigationKey builds the generated key for a navigation position.
+func navigationKey(name string, target int) api.Key {
+	key := api.Key{SwitchPage: target + 1, KeyHandlerFields: map[string]string{navigationField: name}}
+	icon, err := navIconPath(name)
+	if err != nil {
+		logError(categoryUI, "Unable to create navigation icon", err)
+		key.Text = name
+		return key
+	}
+	key.Icon = icon
+	return key
+}
+
+// isNavigationKey reports whether key was generated for navigation.
+func isNavigationKey(key api.Key) bool {
+	return key.KeyHandlerFields[navigationField] != ""
+}
+
+// navigationSlotFree reports whether a navigation key may take the place of
+// key: generated keys give way, folder back keys move elsewhere.
+func navigationSlotFree(key api.Key) bool {
+	return keyEmpty(key) || isNavigationKey(key) || isFolderBackKey(key)
+}
+
+// applyNavigation writes the reserved navigation keys onto every page of
+// deck, pointing at the page's neighbours. Previous and next wrap around.
+// Keys of the user in a reserved position are kept, which is logged;
+// setNavigation asks before clearing them. Run it before syncFolders, which
+// moves the back keys it replaced out of the way.
+func applyNavigation(deck *api.Deck, nav navigationSettings) {
+	if !nav.Enabled {
+		return
+	}
+	count := len(deck.Pages)
+	kept := 0
+	for p := range deck.Pages {
+		set := func(index int, key api.Key) {
+			if index < 0 || index >= len(deck.Pages[p]) {
+				return
+			}
+			if !navigationSlotFree(deck.Pages[p][index]) {
+				kept++
+				return
+			}
+			deck.Pages[p][index] = key
+		}
+		set(nav.Previous, navigationKey(navPrevious, (p+count-1)%count))
+		set(nav.Next, navigationKey(navNext, (p+1)%count))
+		set(nav.Home, navigationKey(navHome, 0))
+	}
+	if kept > 0 {
+		logWarning(categoryUI, fmt.Sprintf("%d keys of %s are in navigation positions and were kept", kept, deck.Serial))
+	}
+}
+
+// navigationClashes returns the changes clearing the keys of the user that
+// are in the positions nav reserves on deck.
+func navigationClashes(deck *api.Deck, nav navigationSettings) []keyChange {
+	if !nav.Enabled {
+		return nil
+	}
+	var changes []keyChange
+	reserved := nav.reserved()
+	for p := range deck.Pages {
+		for i, key := range deck.Pages[p] {
+			if reserved[i] && !navigationSlotFree(key) {
+				changes = append(changes, keyChange{serial: deck.Serial, page: p, index: i, before: copyKey(key), after: api.Key{}})
+			}
+		}
+	}
+	return changes
+}
+
+// clearNavigation removes generated navigation keys from positions that are
+// no longer reserved by nav.
+func clearNavigation(deck *api.Deck, nav navigationSettings) {
+	for p := range deck.Pages {
+		for i, key := range deck.Pages[p] {
+			reserved := nav.Enabled && (i == nav.Previous || i == nav.Next || i == nav.Home)
+			if !reserved && isNavigationKey(key) {
+				deck.Pages[p][i] = api.Key{}
+			}
+		}
+	}
+}
+
+// setNavigation changes the navigation settings of the current deck and
+// regenerates its navigation keys. Keys in the way are only replaced once the
+// user agrees, as one undo step.
+func (e *editor) setNavigation(nav navigationSettings) {
+	deck := e.currentDeviceConfig
+	clashes := navigationClashes(deck, nav)
+	apply := func() {
+		e.settings.deck(deck.Serial).Navigation = nav
+		clearNavigation(deck, nav)
+		e.applyChanges(clashes)
+		e.pagesChanged(e.currentDevice.Page)
+	}
+	if len(clashes) == 0 {
+		apply()
+		return
+	}
+	dialog.ShowConfirm(lang.L("Replace Keys?"),
+		fmt.Sprintf(lang.L("%d keys are in the navigation positions and will be replaced. Undo brings them back."), len(clashes)),
+		func(ok bool) {
+			if ok {
+				apply()
+			}
+		}, e.win)
+}
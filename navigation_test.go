@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestApplyNavigation(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	nav := navigationSettings{Enabled: true, Previous: 0, Next: 1, Home: -1}
+	folder := api.Key{Text: "folder", SwitchPage: 2, KeyHandlerFields: map[string]string{folderField: "true"}}
+	tests := []struct {
+		name  string
+		pages []api.Page
+		check func(t *testing.T, deck *api.Deck)
+	}{
+		{"empty positions", []api.Page{{}, {}, {}}, func(t *testing.T, deck *api.Deck) {
+			for p, page := range deck.Pages {
+				prev, next := page[0], page[1]
+				if !isNavigationKey(prev) || !isNavigationKey(next) || prev.SwitchPage != (p+2)%3+1 || next.SwitchPage != (p+1)%3+1 {
+					t.Errorf("page %d has previous %d and next %d", p+1, prev.SwitchPage, next.SwitchPage)
+				}
+			}
+		}},
+		{"user key in the way", []api.Page{{{Text: "mine"}}, {}}, func(t *testing.T, deck *api.Deck) {
+			if deck.Pages[0][0].Text != "mine" || !isNavigationKey(deck.Pages[1][0]) {
+				t.Errorf("got %+v and %+v, want the user key kept", deck.Pages[0][0], deck.Pages[1][0])
+			}
+		}},
+		{"old navigation key", []api.Page{{navigationKey(navNext, 5)}}, func(t *testing.T, deck *api.Deck) {
+			if key := deck.Pages[0][0]; key.SwitchPage != 1 || key.KeyHandlerFields[navigationField] != navPrevious {
+				t.Errorf("old navigation key became %+v", key)
+			}
+		}},
+		{"back key in the way", []api.Page{{{}, {}, folder}, {folderBack(0)}}, func(t *testing.T, deck *api.Deck) {
+			child := deck.Pages[1]
+			if !isNavigationKey(child[0]) || !isNavigationKey(child[1]) || !isFolderBackKey(child[2]) {
+				t.Errorf("folder page starts %+v, want navigation then the back key", child[:3])
+			}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, deck := testDeck("A", test.pages...)
+			applyNavigation(&deck, nav)
+			syncFolders(&deck, nav.reserved())
+			test.check(t, &deck)
+		})
+	}
+}
+
+func TestNavigationKeysWithoutIconAreCleared(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	key := navigationKey(navHome, 0)
+	key.Icon = ""
+	key.Text = navHome
+	_, deck := testDeck("A", api.Page{key})
+	clearNavigation(&deck, navigationSettings{})
+	if !keyEmpty(deck.Pages[0][0]) {
+		t.Errorf("navigation key without its icon was kept: %+v", deck.Pages[0][0])
+	}
+}
+
+func TestSetNavigationAsksBeforeReplacing(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "mine"}}, api.Page{})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	nav := navigationSettings{Enabled: true, Previous: 0, Next: -1, Home: -1}
+
+	e.setNavigation(nav)
+	if e.deckConfig("A").Pages[0][0].Text != "mine" || e.settings.deck("A").Navigation.Enabled {
+		t.Fatal("navigation replaced a key before asking")
+	}
+	test.Tap(findButton(t, e.win.Canvas().Overlays().Top(), "Yes"))
+	if key := e.deckConfig("A").Pages[0][0]; !isNavigationKey(key) {
+		t.Fatalf("first key is %+v after agreeing, want a navigation key", key)
+	}
+	e.undo()
+	if e.deckConfig("A").Pages[0][0].Text != "mine" {
+		t.Error("undo did not bring back the replaced key")
+	}
+}
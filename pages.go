@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fyne.io/fyne/v2/dialog"
+	"github.com/unix-streamdeck/api"
+)
+
+// remapSwitchPages rewrites every SwitchPage link in deck after its pages
+// have moved, including those of the gestures. mapping takes and returns zero
+// based page indices, returning -1 for a page that no longer exists, which
+// clears the link.
+func remapSwitchPages(deck *api.Deck, mapping func(page int) int) {
+	for p := range deck.Pages {
+		for i := range deck.Pages[p] {
+			key := &deck.Pages[p][i]
+			if key.SwitchPage != 0 {
+				key.SwitchPage = mapping(key.SwitchPage-1) + 1
+			}
+			for _, gesture := range []string{gestureLongPress, gestureDoublePress} {
+				remapGesturePage(key, gesture, mapping)
+			}
+		}
+	}
+}
+
+// remapPageLinks rewrites the SwitchPage links and the Switch Page steps of
+// the macros in deck after its pages have moved, as remapSwitchPages does.
+func remapPageLinks(deck *api.Deck, mapping func(page int) int) {
+	remapSwitchPages(deck, mapping)
+	for p := range deck.Pages {
+		for i := range deck.Pages[p] {
+			remapMacroPages(&deck.Pages[p][i], mapping, deck.Serial)
+		}
+	}
+}
+
+// remapStartupPage keeps the startup page of a deck on the same page after its
+// pages moved, falling back to the first page if it was removed.
+func (e *editor) remapStartupPage(serial string, mapping func(page int) int) {
+	device := &e.settings.deck(serial).Device
+	page := mapping(device.StartupPage)
+	if page < 0 {
+		page = 0
+	}
+	if page != device.StartupPage {
+		device.StartupPage = page
+		e.saveSettings()
+	}
+}
+
+// pagesChanged regenerates folder back keys and navigation keys after pages
+// were added, removed or reordered, pushes the config to the daemon and
+// shows page.
+func (e *editor) pagesChanged(page int) {
+	applyNavigation(e.currentDeviceConfig, e.settings.deck(e.currentDevice.Serial).Navigation)
+	syncFolders(e.currentDeviceConfig, e.settings.deck(e.currentDevice.Serial).Navigation.reserved())
+	err := e.pushConfig()
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	if page >= len(e.currentDeviceConfig.Pages) {
+		page = len(e.currentDeviceConfig.Pages) - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	e.setPage(page, true)
+}
+
+// removePage deletes a page of the current deck, clearing links to it and
+// renumbering links to the pages after it.
+func (e *editor) removePage(index int) {
+	deck := e.currentDeviceConfig
+	if len(deck.Pages) == 1 {
+		e.reset()
+		return
+	}
+	deck.Pages = append(deck.Pages[:index], deck.Pages[index+1:]...)
+	mapping := func(page int) int {
+		if page == index {
+			return -1
+		} else if page > index {
+			return page - 1
+		}
+		return page
+	}
+	remapPageLinks(deck, mapping)
+	e.remapStartupPage(deck.Serial, mapping)
+	e.remapUndo(deck.Serial, mapping)
+	e.pagesChanged(index - 1)
+}
+
+// movePage moves a page of the current deck to a new position, keeping every
+// link pointing at the same pages.
+func (e *editor) movePage(from, to int) {
+	deck := e.currentDeviceConfig
+	if from == to || to < 0 || to >= len(deck.Pages) {
+		return
+	}
+	moved := deck.Pages[from]
+	var pages []api.Page
+	pages = append(pages, deck.Pages[:from]...)
+	pages = append(pages, deck.Pages[from+1:]...)
+	pages = append(pages[:to], append([]api.Page{moved}, pages[to:]...)...)
+	deck.Pages = pages
+	mapping := func(page int) int {
+		switch {
+		case page == from:
+			return to
+		case from < to && page > from && page <= to:
+			return page - 1
+		case from > to && page >= to && page < from:
+			return page + 1
+		}
+		return page
+	}
+	remapPageLinks(deck, mapping)
+	e.remapStartupPage(deck.Serial, mapping)
+	e.remapUndo(deck.Serial, mapping)
+	e.pagesChanged(to)
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	emptyPageTemplate = "Empty Page"
+)
+
+// pageTemplate is a saved page layout that new pages can be created from.
+type pageTemplate struct {
+	Name string   `json:"name"`
+	Page api.Page `json:"page"`
+}
+
+// builtinPageTemplate generates a page for a deck with size keys that will
+// have pageCount pages once the new page is added.
+type builtinPageTemplate struct {
+	name     string
+	generate func(size, pageCount int) api.Page
+}
+
+var builtinPageTemplates = []builtinPageTemplate{
+	{name: "Media Controls", generate: mediaPage},
+	{name: "OBS Scenes", generate: obsPage},
+	{name: "Navigation", generate: navigationPage},
+	{name: "Number Pad", generate: numberPadPage},
+}
+
+func keybindKey(text, keybind string) api.Key {
+	return api.Key{Text: text, Keybind: keybind, TextAlignment: "MIDDLE"}
+}
+
+func mediaPage(size, pageCount int) api.Page {
+	return api.Page{
+		keybindKey("Prev", "XF86AudioPrev"),
+		keybindKey("Play\nPause", "XF86AudioPlay"),
+		keybindKey("Next", "XF86AudioNext"),
+		keybindKey("Stop", "XF86AudioStop"),
+		keybindKey("Mute", "XF86AudioMute"),
+		keybindKey("Vol -", "XF86AudioLowerVolume"),
+		keybindKey("Vol +", "XF86AudioRaiseVolume"),
+		keybindKey("Mic\nMute", "XF86AudioMicMute"),
+	}
+}
+
+// obsPage binds keys to ctrl+alt hotkeys, which are expected to be assigned
+// to scenes and recording in OBS's own hotkey settings.
+func obsPage(size, pageCount int) api.Page {
+	var page api.Page
+	for i := 0; i < size-2 && i < 9; i++ {
+		page = append(page, keybindKey(fmt.Sprintf("Scene\n%d", i+1), fmt.Sprintf("ctrl+alt+%d", i+1)))
+	}
+	return append(page,
+		keybindKey("Record", "ctrl+alt+r"),
+		keybindKey("Stream", "ctrl+alt+s"))
+}
+
+// navigationPage has one key switching to each page of the deck.
+func navigationPage(size, pageCount int) api.Page {
+	var page api.Page
+	for i := 0; i < size && i < pageCount; i++ {
+		page = append(page, api.Key{Text: fmt.Sprintf("Page\n%d", i+1), SwitchPage: i + 1, TextAlignment: "MIDDLE"})
+	}
+	return page
+}
+
+func numberPadPage(size, pageCount int) api.Page {
+	var page api.Page
+	for _, key := range []string{"7", "8", "9", "4", "5", "6", "1", "2", "3", "0"} {
+		page = append(page, keybindKey(key, "KP_"+key))
+	}
+	return append(page,
+		keybindKey(".", "KP_Decimal"),
+		keybindKey("Enter", "KP_Enter"),
+		keybindKey("+", "KP_Add"),
+		keybindKey("-", "KP_Subtract"),
+		keybindKey("*", "KP_Multiply"),
+		keybindKey("/", "KP_Divide"))
+}
+
+// fitPage pads or truncates page to exactly size keys, copying each key.
+func fitPage(page api.Page, size int) api.Page {
+	fitted := make(api.Page, size)
+	for i := 0; i < size && i < len(page); i++ {
+		fitted[i] = copyKey(page[i])
+	}
+	return fitted
+}
+
+// cutKeys counts the keys of pages that fitPage leaves out for a deck of
+// size keys.
+func cutKeys(pages []api.Page, size int) int {
+	cut := 0
+	for _, page := range pages {
+		for i := size; i < len(page); i++ {
+			if !keyEmpty(page[i]) {
+				cut++
+			}
+		}
+	}
+	return cut
+}
+
+func (e *editor) pageTemplateNames() []string {
+	names := []string{emptyPageTemplate}
+	for _, t := range builtinPageTemplates {
+		names = append(names, t.name)
+	}
+	for _, t := range e.templates.Pages {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// templatePage builds the keys of the named built-in or saved template for a
+// deck of size keys that will have pageCount pages, before they are fitted to
+// the deck. The empty page template has none.
+func (e *editor) templatePage(name string, size, pageCount int) api.Page {
+	for _, t := range builtinPageTemplates {
+		if t.name == name {
+			return t.generate(size, pageCount)
+		}
+	}
+	for _, t := range e.templates.Pages {
+		if t.Name == name {
+			return t.Page
+		}
+	}
+	return nil
+}
+
+// pageFromTemplate builds a page for the current device from the named
+// built-in or saved template.
+func (e *editor) pageFromTemplate(name string) api.Page {
+	size := e.currentDevice.Cols * e.currentDevice.Rows
+	return fitPage(e.templatePage(name, size, len(e.currentDeviceConfig.Pages)+1), size)
+}
+
+// droppedKeysLabel returns a warning label, hidden until update is called
+// with a number of keys that do not fit the deck.
+func droppedKeysLabel() (label *widget.Label, update func(cut int)) {
+	label = widget.NewLabel("")
+	label.Importance = widget.WarningImportance
+	label.Hide()
+	return label, func(cut int) {
+		label.SetText(fmt.Sprintf(lang.L("%d keys do not fit and are left out."), cut))
+		setVisible(label, cut > 0)
+	}
+}
+
+// addPage appends page to the current deck and switches to it.
+func (e *editor) addPage(page api.Page) {
+	e.currentDeviceConfig.Pages = append(e.currentDeviceConfig.Pages, page)
+	e.pagesChanged(len(e.currentDeviceConfig.Pages) - 1)
+}
+
+// showNewPage asks which template to create a new page from.
+func (e *editor) showNewPage() {
+	names := e.pageTemplateNames()
+	templates := widget.NewSelect(localise(names), nil)
+	templates.SetSelected(lang.L(emptyPageTemplate))
+	saveCurrent := widget.NewButton(lang.L("Save Current Page as Template"), e.savePageTemplate)
+	dropped, updateDropped := droppedKeysLabel()
+	templates.OnChanged = func(selected string) {
+		size := e.currentDevice.Cols * e.currentDevice.Rows
+		page := e.templatePage(delocalise(names, selected), size, len(e.currentDeviceConfig.Pages)+1)
+		updateDropped(cutKeys([]api.Page{page}, size))
+	}
+
+	dialog.ShowCustomConfirm(lang.L("New Page"), lang.L("Add"), lang.L("Cancel"), container.NewVBox(
+		widget.NewForm(widget.NewFormItem(lang.L("Template"), templates)),
+		dropped,
+		saveCurrent,
+	), func(ok bool) {
+		if !ok {
+			return
+		}
+		page := e.pageFromTemplate(delocalise(names, templates.Selected))
+		e.promptPlaceholders(page, func(keys []api.Key) {
+			e.addPage(keys)
+		})
+	}, e.win)
+}
+
+// savePageTemplate asks for a name and saves the current page to the library.
+func (e *editor) savePageTemplate() {
+	page := redactPage(e.currentDeviceConfig.Pages[e.currentDevice.Page])
+	name := widget.NewEntry()
+	name.Validator = func(text string) error {
+		if text == "" {
+			return errors.New(lang.L("Name required"))
+		}
+		return nil
+	}
+	dialog.ShowForm(lang.L("Save Page Template"), lang.L("Save"), lang.L("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(lang.L("Name"), name),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		e.templates.merge(&templateLibrary{Pages: []pageTemplate{{Name: name.Text, Page: page}}})
+		e.saveTemplates()
+	}, e.win)
+}
+
+// ensureDecks adds a config entry with one empty page for every connected
+// device that has none, returning the serials that need scaffolding.
+func (e *editor) ensureDecks() []string {
+	var empty []string
+	for _, info := range e.info {
+		deck := e.deckConfig(info.Serial)
+		if deck == nil {
+			e.config.Decks = append(e.config.Decks, api.Deck{Serial: info.Serial})
+			deck = &e.config.Decks[len(e.config.Decks)-1]
+		}
+		if len(deck.Pages) == 0 {
+			deck.Pages = []api.Page{make(api.Page, info.Cols*info.Rows)}
+			empty = append(empty, info.Serial)
+		}
+	}
+	e.currentDeviceConfig = e.deckConfig(e.currentDevice.Serial)
+	return empty
+}
+
+// showScaffoldWizard offers to build a starter deck for a device that has no
+// pages yet, one page per chosen template, and calls done once it is closed.
+func (e *editor) showScaffoldWizard(serial string, done func()) {
+	info := e.deviceInfo(serial)
+	if info == nil {
+		done()
+		return
+	}
+	var names []string
+	for _, t := range builtinPageTemplates {
+		names = append(names, t.name)
+	}
+	size := info.Cols * info.Rows
+	dropped, updateDropped := droppedKeysLabel()
+	choices := widget.NewCheckGroup(localise(names), func(selected []string) {
+		var pages []api.Page
+		for _, chosen := range selected {
+			pages = append(pages, e.templatePage(delocalise(names, chosen), size, len(selected)))
+		}
+		updateDropped(cutKeys(pages, size))
+	})
+	choices.SetSelected(localise([]string{"Navigation", "Media Controls"}))
+
+	content := container.NewVBox(
+		widget.NewLabel(deviceLabel(info)+" "+lang.L("has no pages yet.\nChoose the pages to start with:")),
+		choices,
+		dropped,
+	)
+	dialog.ShowCustomConfirm(lang.L("Set Up Deck"), lang.L("Create"), lang.L("Skip"), content, func(ok bool) {
+		defer done()
+		if !ok || len(choices.Selected) == 0 {
+			return
+		}
+		var pages []api.Page
+		for _, t := range builtinPageTemplates {
+			for _, chosen := range choices.Selected {
+				if lang.L(t.name) == chosen {
+					pages = append(pages, fitPage(t.generate(size, len(choices.Selected)), size))
+				}
+			}
+		}
+		deck := e.deckConfig(serial)
+		deck.Pages = pages
+		applyNavigation(deck, e.settings.deck(serial).Navigation)
+		err := e.pushConfig()
+		if err != nil {
+			dialog.ShowError(err, e.win)
+		}
+		if e.currentDevice.Serial == serial {
+			e.setPage(0, true)
+		}
+	}, e.win)
+}
+
+// showScaffoldWizards runs the set up wizard for each serial in turn, opening
+// the next once the previous one is closed.
+func (e *editor) showScaffoldWizards(serials []string) {
+	if len(serials) == 0 {
+		return
+	}
+	e.showScaffoldWizard(serials[0], func() {
+		e.showScaffoldWizards(serials[1:])
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestScaffoldWizardsOpenInTurn(t *testing.T) {
+	a := &api.StreamDeckInfo{Cols: 5, Rows: 3, IconSize: 72, Serial: "A"}
+	b := &api.StreamDeckInfo{Cols: 3, Rows: 2, IconSize: 72, Serial: "B"}
+	f := newFakeDaemon(&api.Config{}, a, b)
+	e, _ := newTestEditor(t, f)
+
+	overlays := e.win.Canvas().Overlays()
+	if n := len(overlays.List()); n != 1 {
+		t.Fatalf("%d wizards are open, want 1", n)
+	}
+	test.Tap(findButton(t, overlays.Top(), "Skip"))
+	if n := len(overlays.List()); n != 1 {
+		t.Fatalf("%d wizards are open after skipping the first, want 1", n)
+	}
+	test.Tap(findButton(t, overlays.Top(), "Skip"))
+	if n := len(overlays.List()); n != 0 {
+		t.Errorf("%d wizards are still open", n)
+	}
+}
+
+func TestBuiltinTemplatesReportCutKeys(t *testing.T) {
+	e := &editor{templates: &templateLibrary{}}
+	tests := []struct {
+		name      string
+		size, cut int
+	}{
+		{"Media Controls", 6, 2},
+		{"Media Controls", 15, 0},
+		{"Number Pad", 15, 1},
+		{"Number Pad", 32, 0},
+		{"OBS Scenes", 6, 0},
+		{"Navigation", 6, 0},
+	}
+	for _, test := range tests {
+		page := e.templatePage(test.name, test.size, 3)
+		if cut := cutKeys([]api.Page{page}, test.size); cut != test.cut {
+			t.Errorf("%s on a deck of %d keys cuts %d keys, want %d", test.name, test.size, cut, test.cut)
+		}
+	}
+}
+
+func TestScaffoldWizardReportsCutKeys(t *testing.T) {
+	mini := &api.StreamDeckInfo{Cols: 3, Rows: 2, IconSize: 72, Serial: "A"}
+	f := newFakeDaemon(&api.Config{}, mini)
+	e, _ := newTestEditor(t, f)
+
+	var found *widget.Label
+	for _, obj := range test.LaidOutObjects(e.win.Canvas().Overlays().Top()) {
+		if label, ok := obj.(*widget.Label); ok && strings.Contains(label.Text, "do not fit") {
+			found = label
+		}
+	}
+	if found == nil || !found.Visible() || !strings.HasPrefix(found.Text, "2 keys") {
+		t.Fatalf("the wizard does not say the media controls lose 2 keys: %v", found)
+	}
+}
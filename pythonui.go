@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/lang"
+	"github.com/unix-streamdeck/api"
+)
+
+// pythonUIConfig is the .streamdeck_ui.json file of the Python streamdeck_ui
+// app. Older versions wrote the per-serial state as the whole file.
+type pythonUIConfig struct {
+	Version int                     `json:"streamdeck_ui_version"`
+	State   map[string]pythonUIDeck `json:"state"`
+}
+
+type pythonUIDeck struct {
+	Buttons    map[string]map[string]pythonUIButton `json:"buttons"`
+	Brightness int                                  `json:"brightness"`
+}
+
+type pythonUIButton struct {
+	Text              string `json:"text"`
+	Icon              string `json:"icon"`
+	Command           string `json:"command"`
+	Keys              string `json:"keys"`
+	Write             string `json:"write"`
+	SwitchPage        int    `json:"switch_page"`
+	BrightnessChange  int    `json:"brightness_change"`
+	ChangeBrightness  int    `json:"change_brightness"`
+	TextVerticalAlign string `json:"text_vertical_align"`
+	FontSize          int    `json:"font_size"`
+}
+
+// readPythonUIConfig reads a streamdeck_ui config, returning the pages of
+// each deck by serial.
+func readPythonUIConfig(file string) (map[string]*importedProfile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var config pythonUIConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, err
+	}
+	if config.State == nil {
+		err = json.Unmarshal(data, &config.State)
+		if err != nil {
+			return nil, fmt.Errorf("Not a streamdeck_ui config: %w", err)
+		}
+	}
+
+	decks := make(map[string]*importedProfile)
+	for serial, deck := range config.State {
+		profile := &importedProfile{name: serial}
+		for pageID, buttons := range deck.Buttons {
+			p, err := strconv.Atoi(pageID)
+			if err != nil || p < 0 {
+				profile.unmapped = append(profile.unmapped, fmt.Sprintf(lang.L("Page %q skipped, unknown page number"), pageID))
+				continue
+			}
+			for len(profile.pages) <= p {
+				profile.pages = append(profile.pages, api.Page{})
+			}
+			for buttonID, button := range buttons {
+				index, err := strconv.Atoi(buttonID)
+				if err != nil || index < 0 {
+					profile.unmapped = append(profile.unmapped, fmt.Sprintf(lang.L("Page %d: button %q skipped, unknown position"), p+1, buttonID))
+					continue
+				}
+				for len(profile.pages[p]) <= index {
+					profile.pages[p] = append(profile.pages[p], api.Key{})
+				}
+				profile.pages[p][index] = pythonUIKey(profile, p, index, button, deck.Brightness)
+			}
+		}
+		if len(profile.pages) > 0 {
+			decks[serial] = profile
+		}
+	}
+	return decks, nil
+}
+
+func pythonUIKey(profile *importedProfile, p, index int, button pythonUIButton, brightness int) api.Key {
+	key := api.Key{Text: button.Text, TextSize: button.FontSize, Icon: button.Icon}
+	switch button.TextVerticalAlign {
+	case "top", "middle-top":
+		key.TextAlignment = "TOP"
+	case "bottom", "middle-bottom":
+		key.TextAlignment = "BOTTOM"
+	case "middle":
+		key.TextAlignment = "MIDDLE"
+	}
+	if key.Icon != "" {
+		if _, err := os.Stat(key.Icon); err != nil {
+			profile.report(p, index, "icon %s not found", key.Icon)
+		}
+	}
+	if button.SwitchPage > 0 {
+		key.SwitchPage = button.SwitchPage
+	}
+
+	change := button.BrightnessChange
+	if change == 0 {
+		change = button.ChangeBrightness
+	}
+	if change != 0 {
+		// streamdeck_ui changes brightness relative to the current level,
+		// we can only set a fixed level.
+		if brightness == 0 {
+			brightness = 50
+		}
+		key.Brightness = clamp(brightness+change, 1, 100)
+		profile.report(p, index, "brightness change of %+d became a fixed brightness of %d", change, key.Brightness)
+	}
+
+	var steps []macroStep
+	if button.Command != "" {
+		steps = append(steps, macroStep{Type: stepCommand, Value: button.Command})
+	}
+	for _, combo := range strings.Split(button.Keys, ",") {
+		combo = strings.TrimSpace(combo)
+		if combo == "" {
+			continue
+		}
+		steps = append(steps, macroStep{Type: stepKeybind, Value: pythonUIKeybind(combo)})
+	}
+	if button.Write != "" {
+		steps = append(steps, macroStep{Type: stepType, Value: button.Write})
+	}
+	switch {
+	case len(steps) == 1 && steps[0].Type == stepCommand:
+		key.Command = steps[0].Value
+	case len(steps) == 1 && steps[0].Type == stepKeybind:
+		key.Keybind = steps[0].Value
+	case len(steps) > 0:
+		err := setMacro(&key, steps, "")
+		if err != nil {
+			profile.report(p, index, "macro could not be imported: %v", err)
+		}
+	}
+	return key
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// pythonUIKeyNames maps the pynput key names streamdeck_ui uses to xdotool
+// key names. Other names, such as letters and F keys, are the same.
+var pythonUIKeyNames = map[string]string{
+	"cmd": "super", "win": "super", "windows": "super", "meta": "super", "super": "super",
+	"ctrl": "ctrl", "control": "ctrl", "alt": "alt", "alt_gr": "ISO_Level3_Shift", "shift": "shift",
+	"enter": "Return", "return": "Return", "esc": "Escape", "escape": "Escape", "space": "space", "tab": "Tab",
+	"backspace": "BackSpace", "delete": "Delete", "insert": "Insert", "home": "Home", "end": "End",
+	"page_up": "Prior", "page_down": "Next", "up": "Up", "down": "Down", "left": "Left", "right": "Right",
+	"print_screen": "Print", "caps_lock": "Caps_Lock", "num_lock": "Num_Lock", "pause": "Pause", "menu": "Menu",
+	"plus": "plus", "minus": "minus", "comma": "comma", "period": "period", "slash": "slash",
+	"media_play_pause": "XF86AudioPlay", "media_next": "XF86AudioNext", "media_previous": "XF86AudioPrev",
+	"media_volume_up": "XF86AudioRaiseVolume", "media_volume_down": "XF86AudioLowerVolume",
+	"media_volume_mute": "XF86AudioMute",
+}
+
+func pythonUIKeybind(combo string) string {
+	parts := strings.Split(combo, "+")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if name, ok := pythonUIKeyNames[strings.ToLower(part)]; ok {
+			part = name
+		} else if len(part) > 1 && (part[0] == 'f' || part[0] == 'F') {
+			if _, err := strconv.Atoi(part[1:]); err == nil {
+				part = "F" + part[1:]
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "+")
+}
+
+// sortedSerials returns the serials of decks in a stable order.
+func sortedSerials(decks map[string]*importedProfile) []string {
+	serials := make([]string, 0, len(decks))
+	for serial := range decks {
+		serials = append(serials, serial)
+	}
+	sort.Strings(serials)
+	return serials
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const pythonUISample = `{
+  "streamdeck_ui_version": 1,
+  "state": {
+    "CL1": {
+      "brightness": 60,
+      "buttons": {
+        "0": {
+          "0": {"text": "Term", "command": "xterm", "text_vertical_align": "bottom"},
+          "1": {"keys": "ctrl+shift+t"},
+          "2": {"keys": "alt+f4, enter", "write": "bye"},
+          "4": {"text": "Next", "switch_page": 2},
+          "5": {"text": "Dim", "brightness_change": -20}
+        },
+        "1": {
+          "0": {"text": "Back", "switch_page": 1},
+          "1": {"keys": "media_volume_up"}
+        }
+      }
+    },
+    "CL2": {"buttons": {}}
+  }
+}`
+
+func TestReadPythonUIConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".streamdeck_ui.json")
+	err := os.WriteFile(file, []byte(pythonUISample), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decks, err := readPythonUIConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decks) != 1 || decks["CL1"] == nil {
+		t.Fatalf("read decks %v, want only CL1", sortedSerials(decks))
+	}
+	pages := decks["CL1"].pages
+	if len(pages) != 2 {
+		t.Fatalf("read %d pages, want 2", len(pages))
+	}
+	if key := pages[0][0]; key.Text != "Term" || key.Command != "xterm" || key.TextAlignment != "BOTTOM" {
+		t.Errorf("command button read as %+v", key)
+	}
+	if key := pages[0][1]; key.Keybind != "ctrl+shift+t" {
+		t.Errorf("keys read as %q", key.Keybind)
+	}
+	steps, err := parseMacro(pages[0][2].KeyHandlerFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []macroStep{{Type: stepKeybind, Value: "alt+F4"}, {Type: stepKeybind, Value: "Return"}, {Type: stepType, Value: "bye"}}
+	if len(steps) != len(want) {
+		t.Fatalf("keys and write read as %+v, want %+v", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d is %+v, want %+v", i+1, steps[i], want[i])
+		}
+	}
+	if pages[0][4].SwitchPage != 2 || pages[1][0].SwitchPage != 1 {
+		t.Errorf("switch_page read as %d and %d, want 2 and 1", pages[0][4].SwitchPage, pages[1][0].SwitchPage)
+	}
+	if pages[0][5].Brightness != 40 {
+		t.Errorf("brightness change read as brightness %d, want 40", pages[0][5].Brightness)
+	}
+	if pages[1][1].Keybind != "XF86AudioRaiseVolume" {
+		t.Errorf("media key read as %q", pages[1][1].Keybind)
+	}
+	if report := strings.Join(decks["CL1"].unmapped, "\n"); !strings.Contains(report, "brightness change of -20") {
+		t.Errorf("report does not mention the brightness change:\n%s", report)
+	}
+}
+
+func TestMergePagesReplace(t *testing.T) {
+	infoA, deckA := testDeck("A", api.Page{{Text: "old"}}, api.Page{{Text: "old too"}})
+	infoB, deckB := testDeck("B", api.Page{{Text: "untouched"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deckA, deckB}}, infoA, infoB)
+	e, _ := newTestEditor(t, f)
+
+	offset := e.mergePages("A", []api.Page{{{Text: "new", SwitchPage: 1}}}, true)
+	e.pagesChanged(e.currentDevice.Page)
+
+	if offset != 0 {
+		t.Errorf("replacing pages merged from page %d, want 1", offset+1)
+	}
+	pages := pushedPages(f, "A")
+	if len(pages) != 1 || pages[0][0].Text != "new" || pages[0][0].SwitchPage != 1 {
+		t.Errorf("deck A after replacing has %d pages starting with %+v", len(pages), pages[0][0])
+	}
+	if pages := pushedPages(f, "B"); len(pages) != 1 || pages[0][0].Text != "untouched" {
+		t.Error("replacing the pages of deck A changed deck B")
+	}
+}
+
+func TestImportPreviewReportsCutKeys(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "existing"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+
+	page := make(api.Page, 32)
+	page[0] = api.Key{Text: "fits"}
+	page[20] = api.Key{Text: "too far"}
+	page[31] = api.Key{Text: "further"}
+	e.previewDeckImport("xl.json", map[string]*importedProfile{"A": {name: "A", pages: []api.Page{page}}})
+
+	var found *widget.Label
+	for _, obj := range test.LaidOutObjects(e.win.Canvas().Overlays().Top()) {
+		if label, ok := obj.(*widget.Label); ok && strings.Contains(label.Text, "do not fit") {
+			found = label
+		}
+	}
+	if found == nil || !found.Visible() || !strings.HasPrefix(found.Text, "2 keys") {
+		t.Fatalf("the preview does not say 2 keys are left out: %v", found)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	remapTopLeft = "Anchor top-left"
+	remapCentre  = "Centre"
+	remapReflow  = "Reflow into extra pages"
+
+	moreKeyText = "More"
+)
+
+var remapStrategies = []string{remapTopLeft, remapCentre, remapReflow}
+
+// reserved returns the key positions nav writes its keys to.
+func (nav navigationSettings) reserved() map[int]bool {
+	reserved := make(map[int]bool)
+	if nav.Enabled {
+		for _, index := range []int{nav.Previous, nav.Next, nav.Home} {
+			if index >= 0 {
+				reserved[index] = true
+			}
+		}
+	}
+	return reserved
+}
+
+// remapPages lays out the pages of a deck shaped like from on a deck shaped
+// like to, leaving the reserved positions free. Anchor top-left and centre
+// keep every key's row and column, shifted by the offset between the grids,
+// and drop keys that fall outside. Reflow anchors top-left on a deck at least
+// as large and on a smaller one keeps the keys in reading order, continuing a
+// page that is too full on extra pages behind a folder key. SwitchPage
+// numbers and macro page steps are rewritten to the new page numbers. It
+// returns the pages and the number of keys that were dropped.
+func remapPages(pages []api.Page, from, to *api.StreamDeckInfo, strategy string, reserved map[int]bool) ([]api.Page, int) {
+	source := make([]api.Page, len(pages))
+	for p, page := range pages {
+		source[p] = make(api.Page, len(page))
+		for i, key := range page {
+			// The target deck regenerates its own navigation and back keys.
+			if !isNavigationKey(key) && !isFolderBackKey(key) {
+				source[p][i] = copyKey(key)
+			}
+		}
+	}
+
+	reflow := strategy == remapReflow && (to.Cols < from.Cols || to.Rows < from.Rows)
+	layout := func(page api.Page, base int) ([]api.Page, int) {
+		if reflow {
+			return reflowPage(page, to, reserved, base)
+		}
+		anchored, dropped := anchorPage(page, from, to, strategy == remapCentre, reserved)
+		return []api.Page{anchored}, dropped
+	}
+
+	first := make([]int, len(source))
+	count := 0
+	for p, page := range source {
+		first[p] = count
+		laid, _ := layout(page, count)
+		count += len(laid)
+	}
+	mapping := func(page int) int {
+		if page < 0 || page >= len(first) {
+			return -1
+		}
+		return first[page]
+	}
+	remapSwitchPages(&api.Deck{Pages: source}, mapping)
+	for p := range source {
+		for i := range source[p] {
+			remapMacroPages(&source[p][i], mapping, "")
+		}
+	}
+
+	var remapped []api.Page
+	dropped := 0
+	for _, page := range source {
+		laid, d := layout(page, len(remapped))
+		remapped = append(remapped, laid...)
+		dropped += d
+	}
+	return remapped, dropped
+}
+
+// anchorPage moves every key of page to the same row and column on a deck
+// shaped like to, offset to the middle of the grid when centre is set.
+func anchorPage(page api.Page, from, to *api.StreamDeckInfo, centre bool, reserved map[int]bool) (api.Page, int) {
+	dr, dc := 0, 0
+	if centre {
+		dr, dc = (to.Rows-from.Rows)/2, (to.Cols-from.Cols)/2
+	}
+	anchored := make(api.Page, to.Cols*to.Rows)
+	dropped := 0
+	for i, key := range page {
+		if keyEmpty(key) {
+			continue
+		}
+		row, col := i/from.Cols+dr, i%from.Cols+dc
+		index := row*to.Cols + col
+		if row < 0 || row >= to.Rows || col < 0 || col >= to.Cols || reserved[index] {
+			dropped++
+			continue
+		}
+		anchored[index] = key
+	}
+	return anchored, dropped
+}
+
+// reflowPage places the keys of page in reading order on pages shaped like
+// to, numbered from base. When they do not fit, the last free key opens the
+// next page as a folder, which syncFolders gives a back key.
+func reflowPage(page api.Page, to *api.StreamDeckInfo, reserved map[int]bool, base int) ([]api.Page, int) {
+	var keys []api.Key
+	for _, key := range page {
+		if !keyEmpty(key) {
+			keys = append(keys, key)
+		}
+	}
+	var free []int
+	for i := 0; i < to.Cols*to.Rows; i++ {
+		if !reserved[i] {
+			free = append(free, i)
+		}
+	}
+	// Continuation pages keep the first free position for their back key.
+	continued := free
+	if len(free) > 0 {
+		continued = free[1:]
+	}
+
+	var pages []api.Page
+	for {
+		laid := make(api.Page, to.Cols*to.Rows)
+		if len(keys) <= len(free) || len(free) < 2 {
+			n := len(keys)
+			if n > len(free) {
+				n = len(free)
+			}
+			for i, key := range keys[:n] {
+				laid[free[i]] = key
+			}
+			return append(pages, laid), len(keys) - n
+		}
+		n := len(free) - 1
+		for i, key := range keys[:n] {
+			laid[free[i]] = key
+		}
+		keys = keys[n:]
+		laid[free[n]] = api.Key{Text: moreKeyText, SwitchPage: base + len(pages) + 2,
+			KeyHandlerFields: map[string]string{folderField: "true"}}
+		pages = append(pages, laid)
+		free = continued
+	}
+}
+
+// remapMacroPages rewrites the page steps of key's macro with mapping and
+// compiles it again for the deck of serial. Steps to pages that are gone are
+// disabled.
+func remapMacroPages(key *api.Key, mapping func(page int) int, serial string) {
+	steps, err := parseMacro(key.KeyHandlerFields)
+	if err != nil || len(steps) == 0 {
+		return
+	}
+	for i, step := range steps {
+		if step.Type != stepPage {
+			continue
+		}
+		page, err := strconv.Atoi(step.Value)
+		if err != nil || page < 1 {
+			continue
+		}
+		page = mapping(page - 1)
+		if page < 0 {
+			steps[i].Disabled = true
+			continue
+		}
+		steps[i].Value = strconv.Itoa(page + 1)
+	}
+	err = setMacro(key, steps, serial)
+	if err != nil {
+		logError(categoryUI, "Unable to compile the macro of "+key.Text, err)
+	}
+}
+
+// copyDeck lays out the pages of the deck of from on the deck of to, after
+// its pages or in place of them. It returns the number of keys that did not
+// fit; the caller pushes the config.
+func (e *editor) copyDeck(from, to, strategy string, replace bool) int {
+	source := e.deckConfig(from)
+	fromInfo, toInfo := e.deviceInfo(from), e.deviceInfo(to)
+	if source == nil || fromInfo == nil || toInfo == nil {
+		return 0
+	}
+	return e.copyPages(fromInfo, toInfo, source.Pages, strategy, replace)
+}
+
+// copyPages lays out pages taken from the deck of from on the deck of to.
+func (e *editor) copyPages(from, to *api.StreamDeckInfo, pages []api.Page, strategy string, replace bool) int {
+	target := e.deckConfig(to.Serial)
+	if target == nil {
+		return 0
+	}
+	nav := e.settings.deck(to.Serial).Navigation
+	pages, dropped := remapPages(pages, from, to, strategy, nav.reserved())
+	if replace {
+		target.Pages = nil
+		e.forgetUndo(to.Serial)
+	}
+	offset := len(target.Pages)
+	shift := func(page int) int {
+		return page + offset
+	}
+	remapSwitchPages(&api.Deck{Pages: pages}, shift)
+	for p := range pages {
+		for i := range pages[p] {
+			remapMacroPages(&pages[p][i], shift, to.Serial)
+		}
+	}
+	target.Pages = append(target.Pages, pages...)
+	applyNavigation(target, nav)
+	syncFolders(target, nav.reserved())
+	return dropped
+}
+
+// showCopyDeck offers to copy the current deck to another connected deck.
+func (e *editor) showCopyDeck() {
+	var targetNames []string
+	for _, info := range e.info {
+		if info.Serial != e.currentDevice.Serial {
+			targetNames = append(targetNames, deviceLabel(info))
+		}
+	}
+	if len(targetNames) == 0 {
+		dialog.ShowInformation(lang.L("Copy Deck"), lang.L("Connect another Stream Deck to copy this deck to."), e.win)
+		return
+	}
+	serialOf := func(label string) string {
+		return label[strings.LastIndex(label, " ")+1:]
+	}
+
+	from := e.currentDevice.Serial
+	summary := widget.NewLabel("")
+	target := widget.NewSelect(targetNames, nil)
+	strategy := widget.NewRadioGroup(localise(remapStrategies), nil)
+	update := func() {
+		info := e.deviceInfo(serialOf(target.Selected))
+		if info == nil || strategy.Selected == "" {
+			return
+		}
+		pages, dropped := remapPages(e.currentDeviceConfig.Pages, e.currentDevice, info, delocalise(remapStrategies, strategy.Selected),
+			e.settings.deck(info.Serial).Navigation.reserved())
+		text := fmt.Sprintf(lang.L("%d pages become %d pages of %d by %d keys."), len(e.currentDeviceConfig.Pages),
+			len(pages), info.Cols, info.Rows)
+		if dropped > 0 {
+			text += " " + fmt.Sprintf(lang.L("%d keys do not fit and are left out."), dropped)
+		}
+		summary.SetText(text)
+	}
+	target.OnChanged = func(string) { update() }
+	strategy.OnChanged = func(string) { update() }
+	target.SetSelected(targetNames[0])
+	strategy.SetSelected(lang.L(remapTopLeft))
+	mode := widget.NewRadioGroup(localise([]string{importAppend, importReplace}), nil)
+	mode.SetSelected(lang.L(importReplace))
+
+	form := widget.NewForm(
+		widget.NewFormItem(lang.L("Copy To"), target),
+		widget.NewFormItem(lang.L("Layout"), strategy),
+		widget.NewFormItem(lang.L("Existing Pages"), mode),
+	)
+	dialog.ShowCustomConfirm(lang.L("Copy Deck")+": "+deviceLabel(e.currentDevice), lang.L("Copy"), lang.L("Cancel"),
+		container.NewVBox(form, summary), func(ok bool) {
+			if !ok {
+				return
+			}
+			e.copyDeck(from, serialOf(target.Selected), delocalise(remapStrategies, strategy.Selected), mode.Selected == lang.L(importReplace))
+			e.pagesChanged(e.currentDevice.Page)
+		}, e.win)
+}
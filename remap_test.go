@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+var (
+	originalDeck = &api.StreamDeckInfo{Cols: 5, Rows: 3}
+	miniDeck     = &api.StreamDeckInfo{Cols: 3, Rows: 2}
+	xlDeck       = &api.StreamDeckInfo{Cols: 8, Rows: 4}
+)
+
+// fullPage returns a page of size keys, each showing its own position.
+func fullPage(size int) api.Page {
+	page := make(api.Page, size)
+	for i := range page {
+		page[i] = api.Key{Text: string(rune('a' + i))}
+	}
+	return page
+}
+
+func TestRemapAnchorAndCentre(t *testing.T) {
+	page := make(api.Page, 15)
+	page[0] = api.Key{Text: "corner"}
+	page[7] = api.Key{Text: "middle"}
+
+	pages, dropped := remapPages([]api.Page{page}, originalDeck, xlDeck, remapTopLeft, nil)
+	if len(pages) != 1 || dropped != 0 || pages[0][0].Text != "corner" || pages[0][10].Text != "middle" {
+		t.Errorf("anchored top-left to %+v with %d dropped", pages, dropped)
+	}
+
+	pages, dropped = remapPages([]api.Page{page}, originalDeck, xlDeck, remapCentre, nil)
+	if dropped != 0 || pages[0][1].Text != "corner" || pages[0][11].Text != "middle" {
+		t.Errorf("centred on a larger deck to %+v with %d dropped", pages[0], dropped)
+	}
+
+	pages, dropped = remapPages([]api.Page{page}, originalDeck, miniDeck, remapCentre, nil)
+	if dropped != 1 || pages[0][4].Text != "middle" {
+		t.Errorf("centred on a smaller deck to %+v with %d dropped, want only the corner dropped", pages[0], dropped)
+	}
+}
+
+func TestRemapReflowSwitchPage(t *testing.T) {
+	first := fullPage(15)
+	first[14] = api.Key{Text: "to second", SwitchPage: 2}
+	second := make(api.Page, 15)
+	second[0] = api.Key{Text: "to first", SwitchPage: 1}
+
+	pages, dropped := remapPages([]api.Page{first, second}, originalDeck, miniDeck, remapReflow, nil)
+	if dropped != 0 {
+		t.Errorf("reflowing dropped %d keys", dropped)
+	}
+	// 15 keys on a 6 key deck: 5 keys and a folder key, then twice 4 keys
+	// between the back key and another folder key, then the last 2.
+	if len(pages) != 5 {
+		t.Fatalf("reflowed into %d pages, want 4 for the first page and 1 for the second", len(pages))
+	}
+	if more := pages[0][5]; !isFolderKey(more) || more.SwitchPage != 2 {
+		t.Errorf("last key of the first page is %+v, want a folder opening page 2", more)
+	}
+	if key := pages[1][1]; key.Text != "f" {
+		t.Errorf("continuation page starts with %q, want f after the back key", key.Text)
+	}
+	if key := pages[3][2]; key.Text != "to second" || key.SwitchPage != 5 {
+		t.Errorf("switch key reflowed to %+v, want it to open page 5", key)
+	}
+	if key := pages[4][0]; key.SwitchPage != 1 {
+		t.Errorf("switch key on the second page opens page %d, want 1", key.SwitchPage)
+	}
+}
+
+func TestCopyDeck(t *testing.T) {
+	infoA, deckA := testDeck("A", api.Page{{Text: "one", SwitchPage: 2}}, api.Page{{Text: "two"}})
+	infoB := &api.StreamDeckInfo{Cols: 3, Rows: 2, IconSize: 72, Serial: "B"}
+	deckB := api.Deck{Serial: "B", Pages: []api.Page{make(api.Page, 6)}}
+	deckB.Pages[0][0] = api.Key{Text: "kept"}
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deckA, deckB}}, infoA, infoB)
+	e, _ := newTestEditor(t, f)
+
+	dropped := e.copyDeck("A", "B", remapTopLeft, false)
+	e.pagesChanged(e.currentDevice.Page)
+
+	if dropped != 0 {
+		t.Errorf("copying dropped %d keys", dropped)
+	}
+	pages := pushedPages(f, "B")
+	if len(pages) != 3 || len(pages[1]) != 6 || pages[0][0].Text != "kept" {
+		t.Fatalf("deck B has %d pages after copying, want the kept page and 2 of 6 keys", len(pages))
+	}
+	if key := pages[1][0]; key.Text != "one" || key.SwitchPage != 3 {
+		t.Errorf("copied switch key is %+v, want it to open page 3", key)
+	}
+}
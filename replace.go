@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	scopePage   = "Current Page"
+	scopeDeck   = "Current Deck"
+	scopeConfig = "Entire Config"
+
+	fieldCommand       = "Command"
+	fieldURL           = "URL"
+	fieldKeybind       = "Keybind"
+	fieldText          = "Text"
+	fieldIcon          = "Icon Path"
+	fieldHandlerFields = "Handler Fields"
+)
+
+var replaceFields = []string{fieldCommand, fieldURL, fieldKeybind, fieldText, fieldIcon, fieldHandlerFields}
+
+// replacement describes a single property rewritten by find and replace.
+type replacement struct {
+	serial      string
+	page, index int
+	field       string
+	before      string
+	after       string
+}
+
+type replacer func(string) string
+
+func newReplacer(find, replace string, useRegex bool) (replacer, error) {
+	if !useRegex {
+		return func(s string) string {
+			return strings.ReplaceAll(s, find, replace)
+		}, nil
+	}
+	re, err := regexp.Compile(find)
+	if err != nil {
+		return nil, err
+	}
+	return func(s string) string {
+		return re.ReplaceAllString(s, replace)
+	}, nil
+}
+
+// macroStepFields are the replace fields that apply to the steps of a macro,
+// by step type.
+var macroStepFields = map[string]string{stepCommand: fieldCommand, stepURL: fieldURL, stepKeybind: fieldKeybind}
+
+// replaceKey applies r to the selected fields of key, returning the rewritten
+// key and a description of every property that changed. The Command of a
+// macro or toggle key is compiled from its steps or commands, so those are
+// rewritten instead and compiled again for the deck of serial. The editor's
+// own handler fields and Password fields are left alone.
+func replaceKey(key api.Key, fields []string, r replacer, serial string) (api.Key, []replacement) {
+	key = copyKey(key)
+	selected := make(map[string]bool)
+	for _, field := range fields {
+		selected[field] = true
+	}
+	var changes []replacement
+	update := func(field string, value *string) {
+		after := r(*value)
+		if after != *value {
+			changes = append(changes, replacement{field: field, before: *value, after: after})
+			*value = after
+		}
+	}
+	updateMap := func(prefix string, m map[string]string, passwords map[string]bool) {
+		for _, name := range sortedKeys(m) {
+			value := m[name]
+			if isEditorField(name) || passwords[name] || isSecretRef(value) {
+				continue
+			}
+			update(prefix+name, &value)
+			m[name] = value
+		}
+	}
+
+	steps, _ := parseMacro(key.KeyHandlerFields)
+	t, _ := parseToggle(key.KeyHandlerFields)
+	switch {
+	case len(steps) > 0:
+		replaced := len(changes)
+		for i := range steps {
+			if field := macroStepFields[steps[i].Type]; selected[field] {
+				update(field, &steps[i].Value)
+			}
+		}
+		if len(changes) > replaced {
+			err := setMacro(&key, steps, serial)
+			if err != nil {
+				logError(categoryUI, "Unable to compile the macro of "+key.Text, err)
+				changes = changes[:replaced]
+			}
+		}
+	case t != nil && selected[fieldCommand]:
+		replaced := len(changes)
+		update(fieldCommand, &t.Off)
+		update(fieldCommand, &t.On)
+		update(fieldCommand, &t.State)
+		if len(changes) > replaced {
+			err := setToggle(&key, t)
+			if err != nil {
+				logError(categoryUI, "Unable to store the toggle of "+key.Text, err)
+				changes = changes[:replaced]
+			}
+		}
+	}
+
+	for _, field := range fields {
+		switch field {
+		case fieldCommand:
+			if len(steps) == 0 && t == nil {
+				update(field, &key.Command)
+			}
+		case fieldURL:
+			update(field, &key.Url)
+		case fieldKeybind:
+			update(field, &key.Keybind)
+		case fieldText:
+			update(field, &key.Text)
+		case fieldIcon:
+			update(field, &key.Icon)
+		case fieldHandlerFields:
+			var iconPasswords, keyPasswords map[string]bool
+			if module := findModule(key.IconHandler); module != nil {
+				iconPasswords = passwordFields(module.IconFields)
+			}
+			if module := findModule(key.KeyHandler); module != nil {
+				keyPasswords = passwordFields(module.KeyFields)
+			}
+			updateMap("Icon ", key.IconHandlerFields, iconPasswords)
+			updateMap("Key ", key.KeyHandlerFields, keyPasswords)
+		}
+	}
+	return key, changes
+}
+
+// findReplace computes every key change a find and replace would make within
+// scope, without modifying the config.
+func (e *editor) findReplace(scope string, fields []string, r replacer) ([]keyChange, []replacement) {
+	var changes []keyChange
+	var previews []replacement
+	for _, deck := range e.config.Decks {
+		if scope != scopeConfig && deck.Serial != e.currentDevice.Serial {
+			continue
+		}
+		for p, page := range deck.Pages {
+			if scope == scopePage && p != e.currentDevice.Page {
+				continue
+			}
+			for i, key := range page {
+				after, replaced := replaceKey(key, fields, r, deck.Serial)
+				if len(replaced) == 0 {
+					continue
+				}
+				changes = append(changes, keyChange{serial: deck.Serial, page: p, index: i, before: copyKey(key), after: after})
+				for _, rep := range replaced {
+					rep.serial, rep.page, rep.index = deck.Serial, p, i
+					previews = append(previews, rep)
+				}
+			}
+		}
+	}
+	return changes, previews
+}
+
+// showFindReplace opens the bulk find and replace dialog.
+func (e *editor) showFindReplace() {
+	find := widget.NewEntry()
+	replace := widget.NewEntry()
+	useRegex := widget.NewCheck(lang.L("Regular expression"), nil)
+	scopes := []string{scopePage, scopeDeck, scopeConfig}
+	fields := widget.NewCheckGroup(localise(replaceFields), nil)
+	fields.Horizontal = true
+	fields.SetSelected([]string{lang.L(fieldCommand)})
+	scope := widget.NewRadioGroup(localise(scopes), nil)
+	scope.Horizontal = true
+	scope.SetSelected(lang.L(scopePage))
+
+	var changes []keyChange
+	var previews []replacement
+	preview := widget.NewList(
+		func() int {
+			return len(previews)
+		},
+		func() fyne.CanvasObject {
+			return container.NewVBox(widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			rep := previews[id]
+			labels := item.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(fmt.Sprintf("%s (%s)", e.describeLocation(rep.serial, rep.page, rep.index), lang.L(rep.field)))
+			labels[1].(*widget.Label).SetText(fmt.Sprintf("%q → %q", rep.before, rep.after))
+		})
+	summary := widget.NewLabel("")
+
+	apply := widget.NewButton(lang.L("Apply"), nil)
+	apply.Disable()
+	update := func() {
+		changes, previews = nil, nil
+		apply.Disable()
+		summary.SetText("")
+		if find.Text != "" && len(fields.Selected) > 0 {
+			r, err := newReplacer(find.Text, replace.Text, useRegex.Checked)
+			if err != nil {
+				summary.SetText(err.Error())
+			} else {
+				var selected []string
+				for _, field := range fields.Selected {
+					selected = append(selected, delocalise(replaceFields, field))
+				}
+				changes, previews = e.findReplace(delocalise(scopes, scope.Selected), selected, r)
+				summary.SetText(fmt.Sprintf(lang.L("%d changes in %d keys"), len(previews), len(changes)))
+				if len(changes) > 0 {
+					apply.Enable()
+				}
+			}
+		}
+		preview.Refresh()
+	}
+	find.OnChanged = func(string) { update() }
+	replace.OnChanged = func(string) { update() }
+	useRegex.OnChanged = func(bool) { update() }
+	fields.OnChanged = func([]string) { update() }
+	scope.OnChanged = func(string) { update() }
+
+	form := widget.NewForm(
+		widget.NewFormItem(lang.L("Find"), find),
+		widget.NewFormItem(lang.L("Replace"), replace),
+		widget.NewFormItem("", useRegex),
+		widget.NewFormItem(lang.L("Fields"), fields),
+		widget.NewFormItem(lang.L("Scope"), scope),
+	)
+	top := container.NewVBox(form, summary)
+	content := fyne.NewContainerWithLayout(layout.NewBorderLayout(top, apply, nil, nil), top, apply, preview)
+
+	d := dialog.NewCustom(lang.L("Find and Replace"), lang.L("Close"), content, e.win)
+	apply.OnTapped = func() {
+		e.applyChanges(changes)
+		d.Hide()
+	}
+	d.Resize(fyne.NewSize(700, 500))
+	d.Show()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestNewReplacer(t *testing.T) {
+	tests := []struct {
+		find, replace string
+		regex         bool
+		in, want      string
+	}{
+		{"a.c", "x", false, "abc a.c", "abc x"},
+		{"a.c", "x", true, "abc a.c", "x x"},
+		{`(\w+)@host`, "$1@other", true, "me@host", "me@other"},
+		{"", "x", false, "abc", "xaxbxcx"},
+	}
+	for _, test := range tests {
+		r, err := newReplacer(test.find, test.replace, test.regex)
+		if err != nil {
+			t.Errorf("newReplacer(%q, %q, %v) failed: %v", test.find, test.replace, test.regex, err)
+			continue
+		}
+		if got := r(test.in); got != test.want {
+			t.Errorf("replacing %q with %q in %q gives %q, want %q", test.find, test.replace, test.in, got, test.want)
+		}
+	}
+	if _, err := newReplacer("(", "", true); err == nil {
+		t.Error("an invalid regular expression was accepted")
+	}
+}
+
+func TestReplaceKey(t *testing.T) {
+	handlers = append(handlers[:1], &api.Module{Name: "Login", IsKey: true,
+		KeyFields: []api.Field{{Name: "user", Type: "Text"}, {Name: "password", Type: "Password"}}})
+	t.Cleanup(func() {
+		handlers = handlers[:1]
+	})
+	r, _ := newReplacer("old", "new", false)
+
+	var macro api.Key
+	setMacro(&macro, []macroStep{{Type: stepCommand, Value: "old-cmd"}, {Type: stepURL, Value: "http://old"}}, "A")
+	var toggled api.Key
+	setToggle(&toggled, &toggle{ID: "t", Off: "old-off", On: "old-on"})
+
+	tests := []struct {
+		name   string
+		key    api.Key
+		fields []string
+		check  func(t *testing.T, key api.Key)
+		want   int
+	}{
+		{"plain fields", api.Key{Command: "old", Url: "old", Text: "old"}, []string{fieldCommand, fieldText}, func(t *testing.T, key api.Key) {
+			if key.Command != "new" || key.Text != "new" || key.Url != "old" {
+				t.Errorf("got %+v", key)
+			}
+		}, 2},
+		{"module fields", api.Key{KeyHandler: "Login", KeyHandlerFields: map[string]string{
+			"user": "old", "password": "old", folderField: "old", gestureLongPress + "command": "old"}},
+			[]string{fieldHandlerFields}, func(t *testing.T, key api.Key) {
+				fields := key.KeyHandlerFields
+				if fields["user"] != "new" || fields["password"] != "old" || fields[folderField] != "old" || fields[gestureLongPress+"command"] != "old" {
+					t.Errorf("only the user field should change, got %v", fields)
+				}
+			}, 1},
+		{"secret reference", api.Key{KeyHandlerFields: map[string]string{"token": secretRefPrefix + "old"}},
+			[]string{fieldHandlerFields}, func(t *testing.T, key api.Key) {
+				if key.KeyHandlerFields["token"] != secretRefPrefix+"old" {
+					t.Errorf("secret reference rewritten to %q", key.KeyHandlerFields["token"])
+				}
+			}, 0},
+		{"macro steps", macro, []string{fieldCommand}, func(t *testing.T, key api.Key) {
+			steps, _ := parseMacro(key.KeyHandlerFields)
+			want, _ := compileMacro(steps, "A")
+			if steps[0].Value != "new-cmd" || steps[1].Value != "http://old" || key.Command != want {
+				t.Errorf("macro steps %+v compiled to %q", steps, key.Command)
+			}
+		}, 1},
+		{"toggle commands", toggled, []string{fieldCommand}, func(t *testing.T, key api.Key) {
+			got, _ := parseToggle(key.KeyHandlerFields)
+			if got.Off != "new-off" || got.On != "new-on" || key.Command != compileToggle(got) {
+				t.Errorf("toggle %+v compiled to %q", got, key.Command)
+			}
+		}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, changes := replaceKey(test.key, test.fields, r, "A")
+			if len(changes) != test.want {
+				t.Errorf("%d changes, want %d: %+v", len(changes), test.want, changes)
+			}
+			test.check(t, key)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+// searchEntry is one searchable property of a key somewhere in the config.
+type searchEntry struct {
+	serial string
+	page   int
+	index  int
+	field  string
+	value  string
+}
+
+// buildSearchIndex flattens every key of every deck in c into its non-empty
+// searchable properties.
+func buildSearchIndex(c *api.Config) []searchEntry {
+	var entries []searchEntry
+	for _, deck := range c.Decks {
+		for p, page := range deck.Pages {
+			for i, key := range page {
+				add := func(field, value string) {
+					if value != "" {
+						entries = append(entries, searchEntry{serial: deck.Serial, page: p, index: i, field: field, value: value})
+					}
+				}
+				add("Text", key.Text)
+				add("Command", key.Command)
+				add("Keybind", key.Keybind)
+				add("URL", key.Url)
+				add("Icon Handler", key.IconHandler)
+				add("Key Handler", key.KeyHandler)
+				for _, name := range sortedKeys(key.IconHandlerFields) {
+					add("Icon "+name, key.IconHandlerFields[name])
+				}
+				for _, name := range sortedKeys(key.KeyHandlerFields) {
+					add("Key "+name, key.KeyHandlerFields[name])
+				}
+			}
+		}
+	}
+	return entries
+}
+
+func searchIndex(entries []searchEntry, query string) []searchEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	var results []searchEntry
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.value), query) {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describeLocation names the device, page and grid position of a key.
+func (e *editor) describeLocation(serial string, page, index int) string {
+	device := serial
+	position := fmt.Sprintf(lang.L("key %d"), index+1)
+	if info := e.deviceInfo(serial); info != nil {
+		device = deviceLabel(info)
+		if info.Cols > 0 {
+			position = fmt.Sprintf(lang.L("row %d, col %d"), index/info.Cols+1, index%info.Cols+1)
+		}
+	}
+	return fmt.Sprintf(lang.L("%s, page %d, %s"), device, page+1, position)
+}
+
+// showKey switches to the device and page holding a key and selects it.
+func (e *editor) showKey(serial string, page, index int) {
+	info := e.deviceInfo(serial)
+	if info == nil {
+		dialog.ShowError(fmt.Errorf(lang.L("Device %s is not connected"), serial), e.win)
+		return
+	}
+	if e.currentDevice.Serial != serial {
+		e.deviceSelector.SetSelected(deviceLabel(info))
+	}
+	if e.currentDevice.Page != page {
+		e.setPage(page, true)
+	}
+	if index < len(e.buttons) {
+		e.editButton(e.buttons[index].(*button))
+	}
+}
+
+// showSearch opens the search panel over every key of every deck.
+func (e *editor) showSearch() {
+	index := buildSearchIndex(e.config)
+	var results []searchEntry
+
+	var d dialog.Dialog
+	list := widget.NewList(
+		func() int {
+			return len(results)
+		},
+		func() fyne.CanvasObject {
+			return container.NewVBox(widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			result := results[id]
+			labels := item.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(lang.L(result.field) + ": " + result.value)
+			labels[1].(*widget.Label).SetText(e.describeLocation(result.serial, result.page, result.index))
+		})
+	list.OnSelected = func(id widget.ListItemID) {
+		result := results[id]
+		d.Hide()
+		e.showKey(result.serial, result.page, result.index)
+	}
+
+	query := widget.NewEntry()
+	query.SetPlaceHolder(lang.L("Search text, commands, keybinds, URLs, handlers..."))
+	query.OnChanged = func(text string) {
+		results = searchIndex(index, text)
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	content := fyne.NewContainerWithLayout(layout.NewBorderLayout(query, nil, nil, nil), query, list)
+	d = dialog.NewCustom(lang.L("Search"), lang.L("Close"), content, e.win)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+	e.win.Canvas().Focus(query)
+}
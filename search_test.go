@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestSearchIndex(t *testing.T) {
+	c := &api.Config{Decks: []api.Deck{
+		{Serial: "A", Pages: []api.Page{
+			{{Text: "Terminal", Command: "xterm"}, {Url: "https://example.com"}},
+			{{}, {KeyHandler: "Counter", KeyHandlerFields: map[string]string{"mode": "Term count"}}},
+		}},
+		{Serial: "B", Pages: []api.Page{{{Keybind: "ctrl+t"}}}},
+	}}
+	entries := buildSearchIndex(c)
+	if len(entries) != 6 {
+		t.Fatalf("index has %d entries, want one for each non-empty property: %+v", len(entries), entries)
+	}
+
+	tests := []struct {
+		query string
+		want  []searchEntry
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"xterm", []searchEntry{{serial: "A", page: 0, index: 0, field: "Command", value: "xterm"}}},
+		{"  TERM ", []searchEntry{
+			{serial: "A", page: 0, index: 0, field: "Text", value: "Terminal"},
+			{serial: "A", page: 0, index: 0, field: "Command", value: "xterm"},
+			{serial: "A", page: 1, index: 1, field: "Key mode", value: "Term count"},
+		}},
+		{"counter", []searchEntry{{serial: "A", page: 1, index: 1, field: "Key Handler", value: "Counter"}}},
+		{"ctrl+", []searchEntry{{serial: "B", page: 0, index: 0, field: "Keybind", value: "ctrl+t"}}},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		got := searchIndex(entries, test.query)
+		if len(got) != len(test.want) {
+			t.Errorf("%q found %+v, want %+v", test.query, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%q result %d is %+v, want %+v", test.query, i, got[i], test.want[i])
+			}
+		}
+	}
+}
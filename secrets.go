@@ -0,0 +1,432 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/godbus/dbus/v5"
+	"github.com/unix-streamdeck/api"
+)
+
+// secretRefPrefix starts the value stored in the config for a Password field.
+// The secret itself lives in a secretStore under the id that follows it.
+const (
+	secretRefPrefix = "secret://streamdeckui/"
+	redacted        = "<redacted>"
+
+	secretsFile    = "secrets.json"
+	secretsKeyFile = "secrets.key"
+)
+
+// secretStore keeps the values of Password fields out of the config.
+type secretStore interface {
+	Get(id string) (string, error)
+	Set(id, value string) error
+	Delete(id string) error
+}
+
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, secretRefPrefix)
+}
+
+func secretRefID(value string) string {
+	return strings.TrimPrefix(value, secretRefPrefix)
+}
+
+func newSecretRef() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	return secretRefPrefix + hex.EncodeToString(id), nil
+}
+
+// openSecretStore uses the desktop's Secret Service if there is one, falling
+// back to an encrypted file in the editor's config directory.
+func openSecretStore() (secretStore, error) {
+	store, err := newSecretServiceStore()
+	if err == nil {
+		return store, nil
+	}
+	path, err := configFile(secretsFile)
+	if err != nil {
+		return nil, err
+	}
+	return newFileSecretStore(filepath.Dir(path))
+}
+
+// secretServiceStore stores secrets in the default collection of the
+// freedesktop Secret Service, such as GNOME Keyring or KWallet.
+type secretServiceStore struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// secretServiceSecret is the Secret struct of the Secret Service API.
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+const (
+	secretService    = "org.freedesktop.secrets"
+	secretPath       = "/org/freedesktop/secrets"
+	secretCollection = "/org/freedesktop/secrets/aliases/default"
+	noPrompt         = "/"
+)
+
+func newSecretServiceStore() (*secretServiceStore, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(secretService, secretPath).
+		Call("org.freedesktop.Secret.Service.OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, err
+	}
+	return &secretServiceStore{conn: conn, session: session}, nil
+}
+
+func secretAttributes(id string) map[string]string {
+	return map[string]string{"application": appDirName, "id": id}
+}
+
+func (s *secretServiceStore) find(id string) (dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.conn.Object(secretService, secretPath).
+		Call("org.freedesktop.Secret.Service.SearchItems", 0, secretAttributes(id)).
+		Store(&unlocked, &locked)
+	if err != nil {
+		return "", err
+	}
+	if len(unlocked) == 0 {
+		if len(locked) > 0 {
+			return "", errors.New("Secret " + id + " is locked")
+		}
+		return "", errors.New("Secret " + id + " not found")
+	}
+	return unlocked[0], nil
+}
+
+func (s *secretServiceStore) Get(id string) (string, error) {
+	item, err := s.find(id)
+	if err != nil {
+		return "", err
+	}
+	var secret secretServiceSecret
+	err = s.conn.Object(secretService, item).
+		Call("org.freedesktop.Secret.Item.GetSecret", 0, s.session).Store(&secret)
+	return string(secret.Value), err
+}
+
+func (s *secretServiceStore) Set(id, value string) error {
+	props := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant("Stream Deck UI " + id),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(secretAttributes(id)),
+	}
+	secret := secretServiceSecret{Session: s.session, Value: []byte(value), ContentType: "text/plain"}
+	var item, prompt dbus.ObjectPath
+	err := s.conn.Object(secretService, secretCollection).
+		Call("org.freedesktop.Secret.Collection.CreateItem", 0, props, secret, true).Store(&item, &prompt)
+	if err != nil {
+		return err
+	}
+	if prompt != noPrompt {
+		return errors.New("The secret collection is locked")
+	}
+	return nil
+}
+
+func (s *secretServiceStore) Delete(id string) error {
+	item, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	var prompt dbus.ObjectPath
+	return s.conn.Object(secretService, item).Call("org.freedesktop.Secret.Item.Delete", 0).Store(&prompt)
+}
+
+// fileSecretStore keeps secrets AES-GCM encrypted in a JSON file, with the
+// key in a separate file readable only by the user.
+type fileSecretStore struct {
+	mu      sync.Mutex
+	path    string
+	aead    cipher.AEAD
+	secrets map[string]string
+}
+
+func newFileSecretStore(dir string) (*fileSecretStore, error) {
+	keyPath := filepath.Join(dir, secretsKeyFile)
+	key, err := os.ReadFile(keyPath)
+	if os.IsNotExist(err) {
+		key = make([]byte, 32)
+		_, err = rand.Read(key)
+		if err == nil {
+			err = os.WriteFile(keyPath, key, 0600)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &fileSecretStore{path: filepath.Join(dir, secretsFile), aead: aead, secrets: make(map[string]string)}
+	return s, readJSONFile(s.path, &s.secrets)
+}
+
+func (s *fileSecretStore) Get(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := base64.StdEncoding.DecodeString(s.secrets[id])
+	if err != nil {
+		return "", err
+	}
+	if len(data) < s.aead.NonceSize() {
+		return "", errors.New("Secret " + id + " not found")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, []byte(id))
+	return string(plain), err
+}
+
+func (s *fileSecretStore) Set(id, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	s.secrets[id] = base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, []byte(value), []byte(id)))
+	return writeJSONFile(s.path, s.secrets)
+}
+
+func (s *fileSecretStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.secrets, id)
+	return writeJSONFile(s.path, s.secrets)
+}
+
+// secretIDs returns the ids of the secrets c refers to.
+func secretIDs(c *api.Config) map[string]bool {
+	ids := make(map[string]bool)
+	for _, deck := range c.Decks {
+		for _, page := range deck.Pages {
+			for _, key := range page {
+				for _, fields := range []map[string]string{key.IconHandlerFields, key.KeyHandlerFields} {
+					for _, value := range fields {
+						if isSecretRef(value) {
+							ids[secretRefID(value)] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// forgetSecrets deletes the secrets of keys removed since the config was last
+// committed. It is called once the daemon has committed e.config.
+func (e *editor) forgetSecrets() {
+	ids := secretIDs(e.config)
+	if e.secrets != nil {
+		for id := range e.committedSecrets {
+			if ids[id] {
+				continue
+			}
+			err := e.secrets.Delete(id)
+			if err != nil {
+				logError(categoryUI, "Unable to delete secret "+id, err)
+			}
+		}
+	}
+	e.committedSecrets = ids
+}
+
+// secretEntry is a password entry that hands its value to onCommit when it
+// is submitted or loses focus, so the secret store is not written on every
+// keystroke.
+type secretEntry struct {
+	widget.Entry
+	committed string
+	onCommit  func(string)
+}
+
+func newSecretEntry(text string, onCommit func(string)) *secretEntry {
+	s := &secretEntry{committed: text, onCommit: onCommit}
+	s.ExtendBaseWidget(s)
+	s.Password = true
+	s.Text = text
+	s.OnSubmitted = func(string) {
+		s.commit()
+	}
+	return s
+}
+
+func (s *secretEntry) FocusLost() {
+	s.Entry.FocusLost()
+	s.commit()
+}
+
+func (s *secretEntry) commit() {
+	if s.Text == s.committed {
+		return
+	}
+	s.committed = s.Text
+	s.onCommit(s.Text)
+}
+
+// loadPasswordUI builds the entry of a Password field. The value goes into
+// the secret store, with a reference in the config, only if the user asks:
+// streamdeckd cannot resolve references, so its module then gets the
+// reference rather than the value. Otherwise the value is kept in the config.
+func (e *editor) loadPasswordUI(name string, itemMap map[string]string) fyne.CanvasObject {
+	b := e.currentButton
+	value := itemMap[name]
+	stored := isSecretRef(value) && e.secrets != nil
+	if stored {
+		secret, err := e.secrets.Get(secretRefID(value))
+		if err != nil {
+			logError(categoryUI, "Unable to read secret for "+name, err)
+		}
+		value = secret
+	}
+
+	note := widget.NewLabel("")
+	note.Wrapping = fyne.TextWrapWord
+	updateNote := func() {
+		if stored {
+			note.SetText(lang.L("The daemon cannot read the secret store, so the module gets a reference instead of this value."))
+		} else {
+			note.SetText(lang.L("This value is stored in the daemon config in plain text."))
+		}
+	}
+	forget := func() {
+		ref := itemMap[name]
+		if !isSecretRef(ref) || e.secrets == nil {
+			return
+		}
+		err := e.secrets.Delete(secretRefID(ref))
+		if err != nil {
+			logError(categoryUI, "Unable to delete secret for "+name, err)
+		}
+	}
+	save := func(text string) {
+		switch {
+		case text == "":
+			forget()
+			delete(itemMap, name)
+		case !stored:
+			forget()
+			itemMap[name] = text
+		default:
+			ref := itemMap[name]
+			if !isSecretRef(ref) {
+				var err error
+				ref, err = newSecretRef()
+				if err != nil {
+					dialog.ShowError(err, e.win)
+					return
+				}
+			}
+			err := e.secrets.Set(secretRefID(ref), text)
+			if err != nil {
+				dialog.ShowError(err, e.win)
+				return
+			}
+			itemMap[name] = ref
+		}
+		b.updateKey()
+		b.queueRefresh()
+	}
+
+	item := newSecretEntry(value, save)
+	reveal := widget.NewCheck(lang.L("Reveal"), func(on bool) {
+		item.Password = !on
+		item.Refresh()
+	})
+	keep := widget.NewCheck(lang.L("Keep in Secret Store"), nil)
+	keep.SetChecked(stored)
+	keep.OnChanged = func(on bool) {
+		stored = on
+		item.committed = item.Text
+		save(item.Text)
+		updateNote()
+	}
+	if e.secrets == nil {
+		keep.Disable()
+	}
+	updateNote()
+	return container.NewVBox(container.NewBorder(nil, nil, nil, reveal, item), keep, note)
+}
+
+// passwordFields returns the names of the Password fields of a handler.
+func passwordFields(fields []api.Field) map[string]bool {
+	names := make(map[string]bool)
+	for _, field := range fields {
+		if field.Type == "Password" {
+			names[field.Name] = true
+		}
+	}
+	return names
+}
+
+// redactKey returns a copy of key fit for exporting, with any Password field
+// still holding a plain value replaced. Secret references are kept, as they
+// reveal nothing without the user's secret store.
+func redactKey(key api.Key) api.Key {
+	key = copyKey(key)
+	redact := func(fields map[string]string, passwords map[string]bool) {
+		for name, value := range fields {
+			if passwords[name] && value != "" && !isSecretRef(value) {
+				fields[name] = redacted
+			}
+		}
+	}
+	if module := findModule(key.IconHandler); module != nil {
+		redact(key.IconHandlerFields, passwordFields(module.IconFields))
+	}
+	if module := findModule(key.KeyHandler); module != nil {
+		redact(key.KeyHandlerFields, passwordFields(module.KeyFields))
+	}
+	return key
+}
+
+func redactPage(page api.Page) api.Page {
+	redactedPage := make(api.Page, len(page))
+	for i, key := range page {
+		redactedPage[i] = redactKey(key)
+	}
+	return redactedPage
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newFileSecretStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Set("a", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get("a"); err != nil || got != "hunter2" {
+		t.Errorf("read back %q, %v, want hunter2", got, err)
+	}
+	if _, err := s.Get("b"); err == nil {
+		t.Error("reading a missing secret succeeded")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, secretsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("the secrets file holds the secret in plain text")
+	}
+	info, err := os.Stat(filepath.Join(dir, secretsKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("the key file has mode %v, want 0600", info.Mode().Perm())
+	}
+
+	// A secret moved to another id does not decrypt.
+	s.secrets["b"] = s.secrets["a"]
+	if _, err := s.Get("b"); err == nil {
+		t.Error("a secret stored under another id was decrypted")
+	}
+
+	reopened, err := newFileSecretStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := reopened.Get("a"); err != nil || got != "hunter2" {
+		t.Errorf("read back %q, %v after reopening, want hunter2", got, err)
+	}
+	err = reopened.Delete("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, _ = newFileSecretStore(dir)
+	if _, err := reopened.Get("a"); err == nil {
+		t.Error("a deleted secret can still be read")
+	}
+}
+
+func TestSecretsStayOutOfTheDaemonConfig(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	f.modules = []*api.Module{{Name: "Weather", IsKey: true, KeyFields: []api.Field{
+		{Title: "API Key", Name: "api_key", Type: "Password"},
+	}}}
+	e, _ := newTestEditor(t, f)
+
+	test.Tap(e.buttons[0].(*button))
+	e.keyHandler.SetSelected("Weather")
+	ui := formItem(t, e.editorArea, "API Key").(*fyne.Container)
+	entry := ui.Objects[0].(*fyne.Container).Objects[0].(*secretEntry)
+	keep := ui.Objects[1].(*widget.Check)
+	test.Type(entry, "hunter2")
+	entry.FocusLost()
+	if got := e.currentButton.key.KeyHandlerFields["api_key"]; got != "hunter2" {
+		t.Errorf("field holds %q, want the value kept in the config", got)
+	}
+	if note := ui.Objects[2].(*widget.Label).Text; !strings.Contains(note, "daemon config") {
+		t.Errorf("note says %q, want it to say the value is in the daemon config", note)
+	}
+
+	keep.SetChecked(true)
+	store := e.secrets.(*fileSecretStore)
+	ref := e.currentButton.key.KeyHandlerFields["api_key"]
+	if !isSecretRef(ref) || len(store.secrets) != 1 {
+		t.Fatalf("keeping the value in the store left %q with %d secrets, want a reference to one", ref, len(store.secrets))
+	}
+	e.saveConfig()
+	if got := committedPage(t, f, "A", 0)[0].KeyHandlerFields["api_key"]; got != ref {
+		t.Errorf("daemon committed %q, want the reference", got)
+	}
+	c, err := fetchConfig(e.settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Decks[0].Pages[0][0].KeyHandlerFields["api_key"]; got != ref {
+		t.Errorf("read back %q from the daemon, want the reference", got)
+	}
+
+	entry.SetText("")
+	entry.FocusLost()
+	if _, ok := e.currentButton.key.KeyHandlerFields["api_key"]; ok || len(store.secrets) != 0 {
+		t.Errorf("clearing the field left %d secrets", len(store.secrets))
+	}
+
+	entry.SetText("hunter3")
+	entry.FocusLost()
+	e.saveConfig()
+	e.clearKey(e.currentButton)
+	if len(store.secrets) != 1 {
+		t.Error("the secret was deleted before the removed key was saved")
+	}
+	e.saveConfig()
+	if len(store.secrets) != 0 {
+		t.Errorf("%d secrets left after saving without the key", len(store.secrets))
+	}
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+// isSelected reports whether b is part of the current multi-selection.
+func (e *editor) isSelected(b *button) bool {
+	for _, s := range e.selection {
+		if s == b {
+			return true
+		}
+	}
+	return false
+}
+
+// setSelection replaces the multi-selection, redrawing every affected button
+// and switching between the single key editor and the batch editor.
+func (e *editor) setSelection(buttons []*button) {
+	if len(buttons) == 1 {
+		e.editButton(buttons[0])
+		return
+	}
+	old := e.selection
+	e.selection = buttons
+	for _, b := range old {
+		b.Refresh()
+	}
+	for _, b := range buttons {
+		b.Refresh()
+	}
+	e.refreshSelectionEditor()
+}
+
+func (e *editor) clearSelection() {
+	e.setSelection(nil)
+}
+
+// toggleSelection adds or removes b from the selection, as for ctrl-click.
+func (e *editor) toggleSelection(b *button) {
+	var buttons []*button
+	found := false
+	for _, s := range e.selectedButtons() {
+		if s == b {
+			found = true
+			continue
+		}
+		buttons = append(buttons, s)
+	}
+	if !found {
+		buttons = append(buttons, b)
+	}
+	e.setSelection(buttons)
+}
+
+// selectRange selects every key between the current button and b, as for
+// shift-click.
+func (e *editor) selectRange(b *button) {
+	if e.currentButton == nil {
+		e.editButton(b)
+		return
+	}
+	from, to := e.currentButton.keyID, b.keyID
+	if from > to {
+		from, to = to, from
+	}
+	var buttons []*button
+	for _, obj := range e.buttons {
+		btn := obj.(*button)
+		if btn.keyID >= from && btn.keyID <= to {
+			buttons = append(buttons, btn)
+		}
+	}
+	e.setSelection(buttons)
+}
+
+// selectedButtons returns the multi-selection, or the current button alone.
+func (e *editor) selectedButtons() []*button {
+	if len(e.selection) > 0 {
+		return e.selection
+	}
+	if e.currentButton != nil {
+		return []*button{e.currentButton}
+	}
+	return nil
+}
+
+func (e *editor) refreshSelectionEditor() {
+	if e.editorArea == nil {
+		return
+	}
+	if len(e.selection) > 1 {
+		e.editorArea.Objects = []fyne.CanvasObject{e.loadBatchEditor()}
+	} else {
+		e.editorArea.Objects = []fyne.CanvasObject{e.tabs}
+	}
+	e.editorArea.Refresh()
+}
+
+// changeSelection builds one undoable batch applying edit to every selected key.
+func (e *editor) changeSelection(edit func(key *api.Key)) {
+	var changes []keyChange
+	for _, b := range e.selection {
+		after := copyKey(b.key)
+		edit(&after)
+		changes = append(changes, keyChange{serial: e.currentDevice.Serial, page: e.currentDevice.Page, index: b.keyID,
+			before: copyKey(b.key), after: after})
+	}
+	e.applyChanges(changes)
+	e.refreshSelectionEditor()
+}
+
+// commonValue returns the value shared by every selected key, if there is one.
+func (e *editor) commonValue(value func(key api.Key) string) (string, bool) {
+	common := ""
+	for i, b := range e.selection {
+		v := value(b.key)
+		if i == 0 {
+			common = v
+		} else if v != common {
+			return "", false
+		}
+	}
+	return common, true
+}
+
+func handlerName(name string) string {
+	if name == "" {
+		return "Default"
+	}
+	return name
+}
+
+func findModule(name string) *api.Module {
+	for _, mod := range handlers {
+		if mod.Name == name {
+			return mod
+		}
+	}
+	return nil
+}
+
+// loadBatchEditor builds a form of the properties shared by every selected
+// key. Only fields the user changes are written back, all in one undo step.
+func (e *editor) loadBatchEditor() fyne.CanvasObject {
+	var items []*widget.FormItem
+	var apply []func(key *api.Key)
+
+	var iconIds, keyIds []string
+	for _, module := range handlers {
+		if module.IsIcon {
+			iconIds = append(iconIds, module.Name)
+		}
+		if module.IsKey {
+			keyIds = append(keyIds, module.Name)
+		}
+	}
+
+	iconHandler, iconCommon := e.commonValue(func(key api.Key) string { return handlerName(key.IconHandler) })
+	keyHandler, keyCommon := e.commonValue(func(key api.Key) string { return handlerName(key.KeyHandler) })
+
+	addSelect := func(title string, options []string, current string, common bool, set func(key *api.Key, value string)) {
+		changed := false
+		sel := widget.NewSelect(options, func(string) { changed = true })
+		if common {
+			sel.Selected = current
+		}
+		items = append(items, widget.NewFormItem(lang.L(title), sel))
+		apply = append(apply, func(key *api.Key) {
+			if changed {
+				set(key, sel.Selected)
+			}
+		})
+	}
+	addEntry := func(title string, current string, common bool, numeric bool, set func(key *api.Key, value string)) {
+		changed := false
+		entry := widget.NewEntry()
+		if common {
+			entry.Text = current
+		} else {
+			entry.SetPlaceHolder(lang.L("(mixed)"))
+		}
+		entry.OnChanged = func(string) { changed = true }
+		if numeric {
+			entry.Validator = func(text string) error {
+				if text == "" {
+					return nil
+				}
+				_, err := strconv.Atoi(text)
+				return err
+			}
+		}
+		items = append(items, widget.NewFormItem(lang.L(title), entry))
+		apply = append(apply, func(key *api.Key) {
+			if changed {
+				set(key, entry.Text)
+			}
+		})
+	}
+
+	addSelect("Icon Handler", iconIds, iconHandler, iconCommon, func(key *api.Key, value string) {
+		if value == "Default" {
+			value = ""
+		}
+		key.IconHandler = value
+	})
+	addSelect("Key Handler", keyIds, keyHandler, keyCommon, func(key *api.Key, value string) {
+		if value == "Default" {
+			value = ""
+		}
+		key.KeyHandler = value
+	})
+
+	if iconCommon && iconHandler == "Default" {
+		size, common := e.commonValue(func(key api.Key) string {
+			if key.TextSize == 0 {
+				return ""
+			}
+			return strconv.Itoa(key.TextSize)
+		})
+		addEntry("Font Size", size, common, true, func(key *api.Key, value string) {
+			key.TextSize, _ = strconv.Atoi(value)
+		})
+		alignment, common := e.commonValue(func(key api.Key) string { return strings.ToUpper(key.TextAlignment) })
+		addSelect("Text Alignment", []string{"TOP", "MIDDLE", "BOTTOM"}, alignment, common, func(key *api.Key, value string) {
+			key.TextAlignment = value
+		})
+	} else if iconCommon {
+		e.addBatchFields(&items, &apply, iconHandler, "Icon")
+	}
+
+	if keyCommon && keyHandler == "Default" {
+		brightness, common := e.commonValue(func(key api.Key) string { return strconv.Itoa(key.Brightness) })
+		addEntry("Brightness", brightness, common, true, func(key *api.Key, value string) {
+			key.Brightness, _ = strconv.Atoi(value)
+		})
+	} else if keyCommon {
+		e.addBatchFields(&items, &apply, keyHandler, "Key")
+	}
+
+	form := widget.NewForm(items...)
+	form.SubmitText = lang.L("Apply")
+	form.OnSubmit = func() {
+		e.changeSelection(func(key *api.Key) {
+			for _, a := range apply {
+				a(key)
+			}
+		})
+	}
+
+	title := widget.NewLabel(fmt.Sprintf(lang.L("%d keys selected"), len(e.selection)))
+	actions := container.NewHBox(
+		widget.NewButtonWithIcon(lang.L("Copy"), theme.ContentCopyIcon(), e.copyButton),
+		widget.NewButtonWithIcon(lang.L("Clear"), theme.ContentClearIcon(), e.clearSelected),
+		widget.NewButtonWithIcon(lang.L("Delete"), theme.DeleteIcon(), func() {
+			dialog.ShowConfirm(lang.L("Delete keys?"), lang.L("Remove the selected keys and move the following keys up?"),
+				func(ok bool) {
+					if ok {
+						e.deleteSelected()
+					}
+				}, e.win)
+		}),
+		widget.NewButton(lang.L("Select None"), e.clearSelection),
+	)
+	return container.NewVBox(container.NewHBox(title, actions), form)
+}
+
+// addBatchFields adds the module's simple fields shared by every selected key.
+func (e *editor) addBatchFields(items *[]*widget.FormItem, apply *[]func(key *api.Key), handler string, handlerType string) {
+	module := findModule(handler)
+	if module == nil {
+		return
+	}
+	fields := module.IconFields
+	itemMap := func(key *api.Key) map[string]string {
+		if key.IconHandlerFields == nil {
+			key.IconHandlerFields = make(map[string]string)
+		}
+		return key.IconHandlerFields
+	}
+	if handlerType == "Key" {
+		fields = module.KeyFields
+		itemMap = func(key *api.Key) map[string]string {
+			if key.KeyHandlerFields == nil {
+				key.KeyHandlerFields = make(map[string]string)
+			}
+			return key.KeyHandlerFields
+		}
+	}
+	for _, field := range fields {
+		field := field
+		value, common := e.commonValue(func(key api.Key) string { return itemMap(&key)[field.Name] })
+		changed := false
+		var obj fyne.CanvasObject
+		var get func() string
+		switch field.Type {
+		case "Text", "Number":
+			entry := widget.NewEntry()
+			if common {
+				entry.Text = value
+			} else {
+				entry.SetPlaceHolder(lang.L("(mixed)"))
+			}
+			entry.OnChanged = func(string) { changed = true }
+			obj, get = entry, func() string { return entry.Text }
+		case "Select", "TextAlignment":
+			options := field.Values
+			if field.Type == "TextAlignment" {
+				options = []string{"TOP", "MIDDLE", "BOTTOM"}
+			}
+			sel := widget.NewSelect(options, func(string) { changed = true })
+			if common {
+				sel.Selected = value
+			}
+			obj, get = sel, func() string { return sel.Selected }
+		default:
+			continue
+		}
+		*items = append(*items, widget.NewFormItem(field.Title, obj))
+		*apply = append(*apply, func(key *api.Key) {
+			if changed {
+				itemMap(key)[field.Name] = get()
+			}
+		})
+	}
+}
+
+// clearSelected resets every selected key to an empty key.
+func (e *editor) clearSelected() {
+	e.changeSelection(func(key *api.Key) {
+		*key = api.Key{}
+	})
+}
+
+// deleteSelected removes the selected keys from the page, moving the keys
+// after them up and leaving empty keys at the end.
+func (e *editor) deleteSelected() {
+	page := e.currentDeviceConfig.Pages[e.currentDevice.Page]
+	var remaining []api.Key
+	for i, key := range page {
+		deleted := false
+		for _, b := range e.selection {
+			if b.keyID == i {
+				deleted = true
+			}
+		}
+		if !deleted {
+			remaining = append(remaining, key)
+		}
+	}
+	var changes []keyChange
+	for i, key := range page {
+		after := api.Key{}
+		if i < len(remaining) {
+			after = remaining[i]
+		}
+		changes = append(changes, keyChange{serial: e.currentDevice.Serial, page: e.currentDevice.Page, index: i,
+			before: copyKey(key), after: copyKey(after)})
+	}
+	e.applyChanges(changes)
+}
+
+// selectionArea wraps a button grid and lets the user drag a rubber band over
+// it to select several keys at once.
+type selectionArea struct {
+	widget.BaseWidget
+	editor  *editor
+	serial  string
+	content fyne.CanvasObject
+	band    *canvas.Rectangle
+
+	start    fyne.Position
+	dragging bool
+}
+
+func newSelectionArea(content fyne.CanvasObject, serial string, e *editor) *selectionArea {
+	band := canvas.NewRectangle(color.Transparent)
+	band.StrokeWidth = 1
+	band.Hide()
+	s := &selectionArea{editor: e, serial: serial, content: content, band: band}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+func (s *selectionArea) CreateRenderer() fyne.WidgetRenderer {
+	return &selectionAreaRenderer{area: s, objects: []fyne.CanvasObject{s.content, s.band}}
+}
+
+func (s *selectionArea) Dragged(ev *fyne.DragEvent) {
+	if !s.dragging {
+		s.dragging = true
+		s.start = ev.Position.Subtract(ev.Dragged)
+		s.band.FillColor = theme.SelectionColor()
+		s.band.StrokeColor = theme.FocusColor()
+		s.band.Show()
+	}
+	topLeft := fyne.NewPos(fyne.Min(s.start.X, ev.Position.X), fyne.Min(s.start.Y, ev.Position.Y))
+	bottomRight := fyne.NewPos(fyne.Max(s.start.X, ev.Position.X), fyne.Max(s.start.Y, ev.Position.Y))
+	s.band.Move(topLeft)
+	s.band.Resize(fyne.NewSize(bottomRight.X-topLeft.X, bottomRight.Y-topLeft.Y))
+	s.band.Refresh()
+}
+
+func (s *selectionArea) DragEnd() {
+	s.dragging = false
+	s.band.Hide()
+
+	bandPos, bandSize := s.band.Position(), s.band.Size()
+	var buttons []*button
+	for _, obj := range s.editor.deviceButtons[s.serial] {
+		b := obj.(*button)
+		pos, size := b.Position(), b.Size()
+		if pos.X < bandPos.X+bandSize.Width && pos.X+size.Width > bandPos.X &&
+			pos.Y < bandPos.Y+bandSize.Height && pos.Y+size.Height > bandPos.Y {
+			buttons = append(buttons, b)
+		}
+	}
+	if len(buttons) == 1 {
+		s.editor.editButton(buttons[0])
+	} else if len(buttons) > 1 {
+		s.editor.selectDevice(s.serial)
+		s.editor.setSelection(buttons)
+	}
+}
+
+type selectionAreaRenderer struct {
+	area    *selectionArea
+	objects []fyne.CanvasObject
+}
+
+func (r *selectionAreaRenderer) Layout(s fyne.Size) {
+	r.area.content.Resize(s)
+}
+
+func (r *selectionAreaRenderer) MinSize() fyne.Size {
+	return r.area.content.MinSize()
+}
+
+func (r *selectionAreaRenderer) Refresh() {
+	r.area.content.Refresh()
+}
+
+func (r *selectionAreaRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *selectionAreaRenderer) Destroy() {
+	// nothing
+}
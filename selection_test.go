@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestChangeSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		selected []int
+		change   func(e *editor)
+		want     []string
+	}{
+		{"edit", []int{0, 2}, func(e *editor) {
+			e.changeSelection(func(key *api.Key) {
+				key.Text += "!"
+			})
+		}, []string{"one!", "two", "three!", "four"}},
+		{"clear", []int{1, 2}, (*editor).clearSelected, []string{"one", "", "", "four"}},
+		{"delete", []int{0, 2}, (*editor).deleteSelected, []string{"two", "four", "", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, deck := testDeck("A", api.Page{{Text: "one"}, {Text: "two"}, {Text: "three"}, {Text: "four"}})
+			f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+			e, _ := newTestEditor(t, f)
+			var selected []*button
+			for _, i := range test.selected {
+				selected = append(selected, e.buttons[i].(*button))
+			}
+			e.setSelection(selected)
+
+			test.change(e)
+			page := e.deckConfig("A").Pages[0]
+			for i, want := range test.want {
+				if page[i].Text != want {
+					t.Errorf("key %d is %q, want %q", i+1, page[i].Text, want)
+				}
+			}
+			if len(e.undoStack) != 1 {
+				t.Errorf("%d undo steps, want the batch as one", len(e.undoStack))
+			}
+			e.undo()
+			for i, want := range []string{"one", "two", "three", "four"} {
+				if page := e.deckConfig("A").Pages[0]; page[i].Text != want {
+					t.Errorf("key %d is %q after undo, want %q", i+1, page[i].Text, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	settingsFile = "settings.json"
+	noKey        = "None"
+)
+
+// navigationSettings reserves key positions on every page of a deck for
+// generated previous, next and home keys. A position of -1 is not used.
+type navigationSettings struct {
+	Enabled  bool `json:"enabled"`
+	Previous int  `json:"previous"`
+	Next     int  `json:"next"`
+	Home     int  `json:"home"`
+}
+
+// deviceSettings are options of the device itself. The daemon has no call to
+// set them yet, so they are only saved. Zero values leave the daemon's
+// defaults.
+type deviceSettings struct {
+	Brightness  int    `json:"brightness,omitempty"`
+	DimAfter    int    `json:"dim_after,omitempty"`   // minutes idle before dimming
+	SleepAfter  int    `json:"sleep_after,omitempty"` // minutes idle before the keys go dark
+	Screensaver string `json:"screensaver,omitempty"` // image shown while asleep
+	StartupPage int    `json:"startup_page,omitempty"`
+}
+
+// deckSettings are editor options for one deck that the daemon's config has
+// no place for.
+type deckSettings struct {
+	Navigation navigationSettings `json:"navigation"`
+	Variables  map[string]string  `json:"variables,omitempty"`
+	Device     deviceSettings     `json:"device"`
+}
+
+// settings are the editor's own preferences, stored in its config directory.
+type settings struct {
+	Decks      map[string]*deckSettings `json:"decks"`
+	GitHistory bool                     `json:"git_history,omitempty"`
+	SideBySide bool                     `json:"side_by_side,omitempty"`
+	Language   string                   `json:"language,omitempty"` // empty follows the system locale
+}
+
+func loadSettings() (*settings, error) {
+	s := &settings{}
+	err := loadJSON(settingsFile, s)
+	if s.Decks == nil {
+		s.Decks = make(map[string]*deckSettings)
+	}
+	return s, err
+}
+
+func (s *settings) save() error {
+	return saveJSON(settingsFile, s)
+}
+
+// deck returns the settings for serial, creating defaults for it if needed.
+func (s *settings) deck(serial string) *deckSettings {
+	d, ok := s.Decks[serial]
+	if !ok {
+		d = &deckSettings{Navigation: navigationSettings{Previous: -1, Next: -1, Home: -1}}
+		s.Decks[serial] = d
+	}
+	return d
+}
+
+func (e *editor) saveSettings() {
+	err := e.settings.save()
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+}
+
+// keyPositionOptions lists every key of the current device by grid position.
+func (e *editor) keyPositionOptions() []string {
+	options := []string{lang.L(noKey)}
+	for i := 0; i < e.currentDevice.Cols*e.currentDevice.Rows; i++ {
+		options = append(options, e.keyPositionName(i))
+	}
+	return options
+}
+
+func (e *editor) keyPositionName(index int) string {
+	return fmt.Sprintf(lang.L("Row %d, Col %d"), index/e.currentDevice.Cols+1, index%e.currentDevice.Cols+1)
+}
+
+func (e *editor) keyPositionSelect(index int) *widget.Select {
+	options := e.keyPositionOptions()
+	sel := widget.NewSelect(options, nil)
+	if index >= 0 && index+1 < len(options) {
+		sel.SetSelected(options[index+1])
+	} else {
+		sel.SetSelected(options[0])
+	}
+	return sel
+}
+
+func selectedPosition(sel *widget.Select) int {
+	return sel.SelectedIndex() - 1
+}
+
+// showDeckSettings opens the settings of the current deck.
+func (e *editor) showDeckSettings() {
+	deck := e.settings.deck(e.currentDevice.Serial)
+	var apply []func()
+
+	nav := deck.Navigation
+	enabled := widget.NewCheck(lang.L("Reserve navigation keys on every page"), nil)
+	enabled.SetChecked(nav.Enabled)
+	previous := e.keyPositionSelect(nav.Previous)
+	next := e.keyPositionSelect(nav.Next)
+	home := e.keyPositionSelect(nav.Home)
+	if !nav.Enabled && nav.Previous < 0 && nav.Next < 0 {
+		size := e.currentDevice.Cols * e.currentDevice.Rows
+		previous.SetSelectedIndex(size - e.currentDevice.Cols + 1)
+		next.SetSelectedIndex(size)
+	}
+	navForm := widget.NewForm(
+		widget.NewFormItem("", enabled),
+		widget.NewFormItem(lang.L("Previous Page"), previous),
+		widget.NewFormItem(lang.L("Next Page"), next),
+		widget.NewFormItem(lang.L("First Page"), home),
+	)
+	apply = append(apply, func() {
+		e.setNavigation(navigationSettings{Enabled: enabled.Checked, Previous: selectedPosition(previous),
+			Next: selectedPosition(next), Home: selectedPosition(home)})
+	})
+
+	vars := copyFields(deck.Variables)
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	apply = append(apply, func() {
+		deck.Variables = vars
+		err := e.pushConfig()
+		if err != nil {
+			dialog.ShowError(err, e.win)
+		}
+		e.refreshEditor()
+	})
+
+	deviceUI, applyDevice := e.loadDeviceSettingsUI(e.currentDevice, &deck.Device)
+	apply = append(apply, applyDevice)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(lang.L("Navigation"), navForm),
+		container.NewTabItem(lang.L("Variables"), loadVariablesUI(vars)),
+		container.NewTabItem(lang.L("Device"), deviceUI),
+	)
+	d := dialog.NewCustomConfirm(lang.L("Deck Settings")+": "+deviceLabel(e.currentDevice), lang.L("Apply"), lang.L("Cancel"), tabs, func(ok bool) {
+		if !ok {
+			return
+		}
+		for _, a := range apply {
+			a()
+		}
+		e.saveSettings()
+	}, e.win)
+	d.Resize(fyne.NewSize(500, 400))
+	d.Show()
+}
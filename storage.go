@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const (
+	appDirName = "streamdeckui"
+)
+
+// configFile returns the path of name within the editor's own configuration
+// directory, creating the directory if it does not exist yet.
+func configFile(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, appDirName)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// loadJSON reads the config file name into v, leaving v untouched if the file
+// does not exist.
+func loadJSON(name string, v interface{}) error {
+	path, err := configFile(name)
+	if err != nil {
+		return err
+	}
+	return readJSONFile(path, v)
+}
+
+// saveJSON writes v to the config file name.
+func saveJSON(name string, v interface{}) error {
+	path, err := configFile(name)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, v)
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"image/color"
+	"regexp"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	templatesFile      = "templates.json"
+	templatePanelWidth = 220
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// keyTemplate is a configured key saved under a name for reuse. Any of its
+// strings may contain {{name}} placeholders, filled in when it is inserted.
+type keyTemplate struct {
+	Name string  `json:"name"`
+	Key  api.Key `json:"key"`
+}
+
+// templateLibrary is the user's collection of templates, stored as JSON in
+// the editor's config directory so it can be exported and shared.
+type templateLibrary struct {
+	Keys  []keyTemplate  `json:"keys"`
+	Pages []pageTemplate `json:"pages,omitempty"`
+}
+
+func loadTemplates() (*templateLibrary, error) {
+	library := &templateLibrary{}
+	err := loadJSON(templatesFile, library)
+	return library, err
+}
+
+func (l *templateLibrary) save() error {
+	return saveJSON(templatesFile, l)
+}
+
+// merge adds the templates of other, replacing any with the same name.
+func (l *templateLibrary) merge(other *templateLibrary) {
+	for _, t := range other.Keys {
+		replaced := false
+		for i := range l.Keys {
+			if l.Keys[i].Name == t.Name {
+				l.Keys[i] = t
+				replaced = true
+			}
+		}
+		if !replaced {
+			l.Keys = append(l.Keys, t)
+		}
+	}
+	for _, t := range other.Pages {
+		replaced := false
+		for i := range l.Pages {
+			if l.Pages[i].Name == t.Name {
+				l.Pages[i] = t
+				replaced = true
+			}
+		}
+		if !replaced {
+			l.Pages = append(l.Pages, t)
+		}
+	}
+}
+
+// keyPlaceholders returns the distinct placeholder names used by keys, in the
+// order they first appear.
+func keyPlaceholders(keys []api.Key) []string {
+	var names []string
+	seen := make(map[string]bool)
+	collect := replacer(func(s string) string {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+		return s
+	})
+	for _, key := range keys {
+		replaceKey(key, replaceFields, collect, "")
+	}
+	return names
+}
+
+// fillPlaceholders puts values in place of the placeholders of key, for the
+// deck of serial.
+func fillPlaceholders(key api.Key, values map[string]string, serial string) api.Key {
+	key, _ = replaceKey(key, replaceFields, func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return match
+		})
+	}, serial)
+	return key
+}
+
+// promptPlaceholders asks for a value for every placeholder in keys, then
+// calls done with the filled in keys. done is not called if cancelled.
+func (e *editor) promptPlaceholders(keys []api.Key, done func([]api.Key)) {
+	names := keyPlaceholders(keys)
+	if len(names) == 0 {
+		done(keys)
+		return
+	}
+
+	entries := make(map[string]*widget.Entry)
+	var items []*widget.FormItem
+	for _, name := range names {
+		entry := widget.NewEntry()
+		entries[name] = entry
+		items = append(items, widget.NewFormItem(name, entry))
+	}
+	dialog.ShowForm(lang.L("Template Values"), lang.L("Insert"), lang.L("Cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		values := make(map[string]string)
+		for name, entry := range entries {
+			values[name] = entry.Text
+		}
+		var filled []api.Key
+		for _, key := range keys {
+			filled = append(filled, fillPlaceholders(key, values, e.currentDevice.Serial))
+		}
+		done(filled)
+	}, e.win)
+}
+
+// stampTemplate applies a template to every selected key as one undo step.
+func (e *editor) stampTemplate(t keyTemplate) {
+	buttons := e.selectedButtons()
+	e.promptPlaceholders([]api.Key{t.Key}, func(keys []api.Key) {
+		var changes []keyChange
+		for _, b := range buttons {
+			changes = append(changes, keyChange{serial: e.currentDevice.Serial, page: e.currentDevice.Page, index: b.keyID,
+				before: copyKey(b.key), after: copyKey(keys[0])})
+		}
+		e.applyChanges(changes)
+	})
+}
+
+// saveTemplate asks for a name and saves the current key to the library.
+func (e *editor) saveTemplate() {
+	if e.currentButton == nil {
+		return
+	}
+	key := redactKey(e.currentButton.key)
+	name := widget.NewEntry()
+	name.Validator = func(text string) error {
+		if text == "" {
+			return errors.New(lang.L("Name required"))
+		}
+		return nil
+	}
+	dialog.ShowForm(lang.L("Save Template"), lang.L("Save"), lang.L("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(lang.L("Name"), name),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		e.templates.merge(&templateLibrary{Keys: []keyTemplate{{Name: name.Text, Key: key}}})
+		e.saveTemplates()
+	}, e.win)
+}
+
+func (e *editor) saveTemplates() {
+	err := e.templates.save()
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+	if e.templateList != nil {
+		e.templateList.Refresh()
+	}
+}
+
+func (e *editor) importTemplates() {
+	file, err := zenity.SelectFile(zenity.FileFilters{zenity.FileFilter{Name: "Templates", Patterns: []string{"*.json"}}})
+	if err != nil && err.Error() != "dialog canceled" {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	if file == "" {
+		return
+	}
+	library := &templateLibrary{}
+	err = readJSONFile(file, library)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	e.templates.merge(library)
+	e.saveTemplates()
+}
+
+func (e *editor) exportTemplates() {
+	file, err := zenity.SelectFileSave(zenity.ConfirmOverwrite(), zenity.Filename("templates.json"))
+	if err != nil && err.Error() != "dialog canceled" {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	if file == "" {
+		return
+	}
+	err = writeJSONFile(file, e.templates)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+	}
+}
+
+// toggleTemplatePanel shows or hides the template library side panel.
+func (e *editor) toggleTemplatePanel() {
+	if e.templatePanel.Visible() {
+		e.templatePanel.Hide()
+	} else {
+		e.templatePanel.Show()
+	}
+}
+
+func (e *editor) loadTemplatePanel() fyne.CanvasObject {
+	library, err := loadTemplates()
+	if err != nil {
+		logError(categoryUI, "Unable to load templates", err)
+	}
+	e.templates = library
+
+	selected := -1
+	e.templateList = widget.NewList(
+		func() int {
+			return len(e.templates.Keys)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(e.templates.Keys[id].Name)
+		})
+	e.templateList.OnSelected = func(id widget.ListItemID) {
+		selected = id
+	}
+	e.templateList.OnUnselected = func(id widget.ListItemID) {
+		selected = -1
+	}
+
+	actions := container.NewGridWithColumns(2,
+		widget.NewButtonWithIcon(lang.L("Stamp"), theme.ContentPasteIcon(), func() {
+			if selected >= 0 && selected < len(e.templates.Keys) {
+				e.stampTemplate(e.templates.Keys[selected])
+			}
+		}),
+		widget.NewButtonWithIcon(lang.L("Save Key"), theme.DocumentSaveIcon(), e.saveTemplate),
+		widget.NewButtonWithIcon(lang.L("Import"), theme.FolderOpenIcon(), e.importTemplates),
+		widget.NewButtonWithIcon(lang.L("Export"), theme.DownloadIcon(), e.exportTemplates),
+		widget.NewButtonWithIcon(lang.L("Delete"), theme.DeleteIcon(), func() {
+			if selected < 0 || selected >= len(e.templates.Keys) {
+				return
+			}
+			e.templates.Keys = append(e.templates.Keys[:selected], e.templates.Keys[selected+1:]...)
+			e.templateList.UnselectAll()
+			e.saveTemplates()
+		}),
+	)
+	title := widget.NewLabelWithStyle(lang.L("Templates"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	width := canvas.NewRectangle(color.Transparent)
+	width.SetMinSize(fyne.NewSize(templatePanelWidth, 0))
+	e.templatePanel = fyne.NewContainerWithLayout(layout.NewBorderLayout(title, actions, nil, nil), title, actions, width, e.templateList)
+	e.templatePanel.Hide()
+	return e.templatePanel
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ncruces/zenity"
+	"github.com/unix-streamdeck/api"
+)
+
+// toggleField holds the toggle of a two-state key. The daemon has no handler
+// for such keys, so like a macro the toggle is compiled into the key's
+// Command: each press runs the command of the current state, which a file in
+// the user's state directory records, or the exit code of the state command
+// decides, 0 meaning on. The key's own text and icon are its off appearance;
+// the on appearance is kept with the toggle for the editor's preview, as the
+// daemon draws the key the same in both states.
+const toggleField = editorFieldPrefix + "toggle"
+
+// toggle is the pair of commands of a two-state key and its on appearance.
+// ID names its state file.
+type toggle struct {
+	ID     string `json:"id"`
+	Off    string `json:"off"`
+	On     string `json:"on"`
+	State  string `json:"state,omitempty"`
+	OnText string `json:"on_text,omitempty"`
+	OnIcon string `json:"on_icon,omitempty"`
+}
+
+// parseToggle reads the toggle stored in a key's handler fields, if any.
+func parseToggle(fields map[string]string) (*toggle, error) {
+	if fields[toggleField] == "" {
+		return nil, nil
+	}
+	t := &toggle{}
+	err := json.Unmarshal([]byte(fields[toggleField]), t)
+	return t, err
+}
+
+// subshell runs command in a subshell, or does nothing if it is empty.
+func subshell(command string) string {
+	if strings.TrimSpace(command) == "" {
+		return ":"
+	}
+	return "(" + command + ")"
+}
+
+// compileToggle returns the shell command that runs the command of the
+// current state and flips the state.
+func compileToggle(t *toggle) string {
+	if strings.TrimSpace(t.State) != "" {
+		return "if " + subshell(t.State) + " >/dev/null 2>&1; then " + subshell(t.On) + "; else " + subshell(t.Off) + "; fi"
+	}
+	return `f="${XDG_STATE_HOME:-$HOME/.local/state}/streamdeckui/toggle/` + t.ID + `"; ` +
+		`if [ -e "$f" ]; then rm -f "$f"; ` + subshell(t.On) + `; ` +
+		`else mkdir -p "${f%/*}" && touch "$f"; ` + subshell(t.Off) + `; fi`
+}
+
+// setToggle stores t as the toggle of key and compiles it into its Command.
+func setToggle(key *api.Key, t *toggle) error {
+	if t.ID == "" {
+		id := make([]byte, 8)
+		_, err := rand.Read(id)
+		if err != nil {
+			return err
+		}
+		t.ID = hex.EncodeToString(id)
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if key.KeyHandlerFields == nil {
+		key.KeyHandlerFields = make(map[string]string)
+	}
+	key.KeyHandlerFields[toggleField] = string(data)
+	key.Command = compileToggle(t)
+	return nil
+}
+
+// toggleFace returns the text and icon key shows in the given state.
+func toggleFace(key api.Key, on bool) (string, string) {
+	if on {
+		if t, err := parseToggle(key.KeyHandlerFields); err == nil && t != nil {
+			return t.OnText, t.OnIcon
+		}
+	}
+	return key.Text, key.Icon
+}
+
+// createToggle turns the current key into a toggle, its command becoming the
+// off command.
+func (e *editor) createToggle() {
+	b := e.currentButton
+	err := setToggle(&b.key, &toggle{Off: b.key.Command})
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	b.updateKey()
+	e.refreshEditor()
+}
+
+// updateToggle applies change to the toggle of the current key and compiles
+// it again.
+func (e *editor) updateToggle(change func(t *toggle)) {
+	b := e.currentButton
+	t, err := parseToggle(b.key.KeyHandlerFields)
+	if err != nil || t == nil {
+		return
+	}
+	change(t)
+	err = setToggle(&b.key, t)
+	if err != nil {
+		dialog.ShowError(err, e.win)
+		return
+	}
+	b.updateKey()
+	b.queueRefresh()
+}
+
+// removeToggle turns the current key back into a single action key running
+// its off command.
+func (e *editor) removeToggle() {
+	b := e.currentButton
+	t, err := parseToggle(b.key.KeyHandlerFields)
+	if err == nil && t != nil {
+		b.key.Command = t.Off
+	}
+	delete(b.key.KeyHandlerFields, toggleField)
+	b.toggleOn = false
+	b.updateKey()
+	b.Refresh()
+	e.refreshEditor()
+}
+
+// loadToggleUI edits the commands of both states of the current key side by
+// side, or offers to make it a toggle.
+func (e *editor) loadToggleUI() fyne.CanvasObject {
+	fields := e.currentButton.key.KeyHandlerFields
+	t, err := parseToggle(fields)
+	if err != nil || t == nil {
+		create := widget.NewButton(lang.L("Create Toggle"), e.createToggle)
+		if fields[macroField] != "" || err != nil {
+			create.Disable()
+		}
+		return create
+	}
+	entry := func(value string, set func(t *toggle, value string)) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		entry.OnChanged = func(text string) {
+			e.updateToggle(func(t *toggle) {
+				set(t, text)
+			})
+		}
+		return entry
+	}
+	off := entry(t.Off, func(t *toggle, value string) { t.Off = value })
+	on := entry(t.On, func(t *toggle, value string) { t.On = value })
+	state := entry(t.State, func(t *toggle, value string) { t.State = value })
+
+	note := widget.NewLabel(lang.L("If set, the state command's exit code decides the state: 0 is on, anything else off."))
+	note.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(
+		fyne.NewContainerWithLayout(layout.NewGridLayout(2),
+			widget.NewCard("", lang.L("When Off"), widget.NewForm(widget.NewFormItem(lang.L("Command"), off))),
+			widget.NewCard("", lang.L("When On"), widget.NewForm(widget.NewFormItem(lang.L("Command"), on))),
+		),
+		widget.NewForm(widget.NewFormItem(lang.L("State Command"), state)),
+		note,
+		widget.NewButton(lang.L("Remove Toggle"), e.removeToggle),
+	)
+}
+
+// loadToggleIconUI puts the appearance of the off state, the key's own form
+// off, next to the text and icon of the on state, with a button flipping the
+// grid preview between them.
+func (e *editor) loadToggleIconUI(off fyne.CanvasObject) fyne.CanvasObject {
+	t, err := parseToggle(e.currentButton.key.KeyHandlerFields)
+	if err != nil || t == nil {
+		return off
+	}
+	text := widget.NewMultiLineEntry()
+	text.SetText(t.OnText)
+	text.OnChanged = func(value string) {
+		e.updateToggle(func(t *toggle) {
+			t.OnText = value
+		})
+	}
+	icon := widget.NewButton(lang.L("Select Icon"), func() {
+		file, err := zenity.SelectFile(zenity.FileFilters{zenity.FileFilter{Name: "Files", Patterns: []string{"*.png", "*.jpg", "*.jpeg"}}})
+		if err != nil && err.Error() != "dialog canceled" {
+			dialog.ShowError(err, e.win)
+			return
+		}
+		if file != "" {
+			e.updateToggle(func(t *toggle) {
+				t.OnIcon = file
+			})
+		}
+	})
+	clearIcon := widget.NewButton(lang.L("Clear Icon"), func() {
+		e.updateToggle(func(t *toggle) {
+			t.OnIcon = ""
+		})
+	})
+	on := widget.NewForm(
+		widget.NewFormItem(lang.L("Text"), text),
+		widget.NewFormItem(lang.L("Icon"), fyne.NewContainerWithLayout(layout.NewGridLayout(2), icon, clearIcon)),
+	)
+
+	note := widget.NewLabel(lang.L("The daemon shows the off appearance in both states; the on appearance is only previewed here."))
+	note.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(
+		fyne.NewContainerWithLayout(layout.NewGridLayout(2),
+			widget.NewCard("", lang.L("Off"), off),
+			widget.NewCard("", lang.L("On"), on),
+		),
+		widget.NewButton(lang.L("Flip Preview State"), e.currentButton.flipToggle),
+		note,
+	)
+}
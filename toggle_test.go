@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+func TestToggleCommandAlternates(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	log := filepath.Join(dir, "log")
+	var key api.Key
+	err := setToggle(&key, &toggle{Off: "echo on >>" + log, On: "echo off >>" + log})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		out, err := exec.Command("/bin/sh", "-c", key.Command).CombinedOutput()
+		if err != nil {
+			t.Fatalf("toggle command failed: %v: %s", err, out)
+		}
+	}
+	data, _ := os.ReadFile(log)
+	if string(data) != "on\noff\non\n" {
+		t.Errorf("three presses ran %q, want on, off, on", data)
+	}
+
+	err = setToggle(&key, &toggle{Off: "echo off", On: "echo on", State: "false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := exec.Command("/bin/sh", "-c", key.Command).Output()
+	if string(out) != "off\n" {
+		t.Errorf("a failing state command ran %q, want the off command", out)
+	}
+}
+
+func TestTogglePreviewFlips(t *testing.T) {
+	key := api.Key{Text: "Off", Icon: "off.png"}
+	setToggle(&key, &toggle{OnText: "On", OnIcon: "on.png"})
+	info, deck := testDeck("A", api.Page{key})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	b := e.buttons[0].(*button)
+	e.editButton(b)
+
+	if text, icon := b.face(); text != "Off" || icon != "off.png" {
+		t.Errorf("toggle key shows %q and %q, want its off appearance", text, icon)
+	}
+	findButton(t, e.iconDetailSelector, "Flip Preview State").OnTapped()
+	if text, icon := b.face(); text != "On" || icon != "on.png" {
+		t.Errorf("flipped toggle key shows %q and %q, want its on appearance", text, icon)
+	}
+
+	for _, obj := range test.LaidOutObjects(e.keyDetailSelector) {
+		if card, ok := obj.(*widget.Card); ok && card.Subtitle == "When On" {
+			typeText(formEntry(t, card.Content, "Command"), "echo on")
+		}
+	}
+	got, _ := parseToggle(b.key.KeyHandlerFields)
+	if got.On != "echo on" || b.key.Command != compileToggle(got) {
+		t.Errorf("editing the on command gave %+v compiled to %q", got, b.key.Command)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
@@ -15,25 +16,48 @@ import (
 
 type editor struct {
 	currentButton       *button
-	copiedButton        *button
+	selection           []*button
 	config              *api.Config
 	info                []*api.StreamDeckInfo
 	currentDeviceConfig *api.Deck
 	currentDevice       *api.StreamDeckInfo
 	deviceButtons       map[string][]fyne.CanvasObject
-	layouts             map[string]*fyne.Container
+	views               map[string]*deckView
+	deckRow             *fyne.Container
+	status              *widget.Label
 	deviceSelector      *widget.Select
+	drag                *keyDrag
 
 	iconHandler, keyHandler               *widget.Select
 	pageLabel                             *toolbarLabel
 	buttons                               []fyne.CanvasObject
 	keyDetailSelector, iconDetailSelector *fyne.Container
+	editorArea                            *fyne.Container
+	tabs                                  *container.AppTabs
+	undoStack                             [][]keyChange
+	templates                             *templateLibrary
+	templateList                          *widget.List
+	templatePanel                         *fyne.Container
+	settings                              *settings
+	secrets                               secretStore
+	committedSecrets                      map[string]bool
+	events                                *eventLoop
+	history                               historyStore
 
 	win fyne.Window
 }
 
 func newEditor(info []*api.StreamDeckInfo, w fyne.Window) *editor {
-	c, err := conn.GetConfig()
+	s, err := loadSettings()
+	if err != nil {
+		logError(categoryUI, "Unable to load settings", err)
+	}
+	setupLanguage(s.Language)
+	secrets, err := openSecretStore()
+	if err != nil {
+		logError(categoryUI, "Unable to open secret store", err)
+	}
+	c, err := fetchConfig(s)
 	if err != nil {
 		dialog.ShowError(err, w)
 		c = &api.Config{}
@@ -45,12 +69,22 @@ func newEditor(info []*api.StreamDeckInfo, w fyne.Window) *editor {
 			config = &c.Decks[i]
 		}
 	}
-	ed := &editor{config: c, info: info, win: w, currentDevice: currentDevice, currentDeviceConfig: config,
-		deviceButtons: make(map[string][]fyne.CanvasObject), layouts: make(map[string]*fyne.Container)}
-	go ed.registerPageListener() // TODO remove "go" once daemon fixed
+	history, err := openHistory(s.GitHistory)
+	if err != nil {
+		logError(categoryUI, "Unable to open config history", err)
+	}
+	ed := &editor{config: c, info: info, win: w, currentDevice: currentDevice, currentDeviceConfig: config, settings: s, secrets: secrets, committedSecrets: secretIDs(c), history: history,
+		deviceButtons: make(map[string][]fyne.CanvasObject), views: make(map[string]*deckView), events: newEventLoop()}
+	ed.applyStartupPages()
 	return ed
 }
 
+// listen starts delivering daemon events to the editor.
+func (e *editor) listen() {
+	go e.events.run()
+	go e.registerPageListener() // RegisterPageListener blocks for as long as the connection is open
+}
+
 func (e *editor) loadEditor() fyne.CanvasObject {
 
 	var keyIds []string
@@ -72,19 +106,21 @@ func (e *editor) loadEditor() fyne.CanvasObject {
 	e.refreshEditor()
 
 	iconHandler := widget.NewForm(
-		widget.NewFormItem("Icon Handler", e.iconHandler),
+		widget.NewFormItem(lang.L("Icon Handler"), e.iconHandler),
 	)
 	keyHandler := widget.NewForm(
-		widget.NewFormItem("Key Handler", e.keyHandler),
+		widget.NewFormItem(lang.L("Key Handler"), e.keyHandler),
 	)
 	iconForm := fyne.NewContainerWithLayout(layout.NewFormLayout(), fyne.NewContainerWithLayout(layout.NewCenterLayout(), iconHandler), e.iconDetailSelector)
 	keyForm := fyne.NewContainerWithLayout(layout.NewFormLayout(), fyne.NewContainerWithLayout(layout.NewCenterLayout(), keyHandler), e.keyDetailSelector)
 	tabs := container.NewAppTabs(
-		container.NewTabItem("Icon Config", iconForm),
-		container.NewTabItem("Keypress Config", keyForm),
+		container.NewTabItem(lang.L("Icon Config"), iconForm),
+		container.NewTabItem(lang.L("Keypress Config"), keyForm),
 	)
 	tabs.SetTabLocation(container.TabLocationTop)
-	return tabs
+	e.tabs = tabs
+	e.editorArea = fyne.NewContainerWithLayout(layout.NewMaxLayout(), tabs)
+	return e.editorArea
 }
 
 func (e *editor) chooseKeyHandler(name string) {
@@ -104,11 +140,11 @@ func (e *editor) chooseHandler(name string, handlerType string) {
 		}
 	}
 	if module == nil {
-		fyne.LogError("Handler not found "+name, nil)
+		logWarning(categoryUI, "Handler not found "+name)
 		return
 	}
 	if (handlerType == "Key" && !module.IsKey) || (handlerType == "Icon" && !module.IsIcon) {
-		fyne.LogError("Handler not found "+name, nil)
+		logWarning(categoryUI, "Handler not found "+name)
 		return
 	}
 	var ui fyne.CanvasObject
@@ -156,6 +192,7 @@ func (e *editor) chooseHandler(name string, handlerType string) {
 
 	if ui != nil {
 		if handlerType == "Key" {
+			ui = container.NewVBox(ui, loadGesturesUI(e))
 			e.keyDetailSelector.Objects = []fyne.CanvasObject{ui}
 		} else {
 			e.iconDetailSelector.Objects = []fyne.CanvasObject{ui}
@@ -169,13 +206,20 @@ func (e *editor) chooseHandler(name string, handlerType string) {
 }
 
 func (e *editor) editButton(b *button) {
+	e.selectDevice(b.device.Serial)
 	old := e.currentButton
 	e.currentButton = b
+	if len(e.selection) > 0 {
+		e.clearSelection()
+	}
 
-	old.Refresh()
+	if old != nil {
+		old.Refresh()
+	}
 	b.Refresh()
 
 	e.refreshEditor()
+	e.announce(b)
 }
 
 func (e *editor) emptyPage() api.Page {
@@ -189,10 +233,9 @@ func (e *editor) emptyPage() api.Page {
 
 func (e *editor) pageListener(serial string, page int32) {
 	if e.currentDevice.Serial != serial {
-		for i := range e.info {
-			if e.info[i].Serial == serial {
-				e.info[i].Page = int(page)
-			}
+		if info := e.deviceInfo(serial); info != nil {
+			info.Page = int(page)
+			e.refreshDeck(info)
 		}
 		return
 	}
@@ -221,24 +264,48 @@ func (e *editor) refreshEditor() {
 }
 
 func (e *editor) refresh() {
-	for _, b := range e.buttons {
-		if e.currentButton == nil {
-			e.currentButton = b.(*button)
+	for _, info := range e.info {
+		e.refreshDeck(info)
+	}
+
+	e.refreshEditor()
+}
+
+// refreshDeck shows the keys of the current page of the deck of info on its
+// buttons.
+func (e *editor) refreshDeck(info *api.StreamDeckInfo) {
+	deck := e.deckConfig(info.Serial)
+	if deck == nil || info.Page >= len(deck.Pages) {
+		return
+	}
+	for _, obj := range e.deviceButtons[info.Serial] {
+		b := obj.(*button)
+		if e.currentButton == nil && info == e.currentDevice {
+			e.currentButton = b
 		}
-		if b.(*button).keyID >= len(e.currentDeviceConfig.Pages[e.currentDevice.Page]) {
-			e.currentDeviceConfig.Pages[e.currentDevice.Page] = append(e.currentDeviceConfig.Pages[e.currentDevice.Page], api.Key{})
+		if b.keyID >= len(deck.Pages[info.Page]) {
+			deck.Pages[info.Page] = append(deck.Pages[info.Page], api.Key{})
 		}
-		b.(*button).key = e.currentDeviceConfig.Pages[e.currentDevice.Page][b.(*button).keyID]
+		b.key = deck.Pages[info.Page][b.keyID]
 		b.Refresh()
 	}
-
-	e.refreshEditor()
+	if v, ok := e.views[info.Serial]; ok {
+		v.page.SetText(fmt.Sprintf("%d/%d", info.Page+1, len(deck.Pages)))
+	}
 }
 
+// registerPageListener runs on its own goroutine, so page changes are posted
+// to the event loop rather than applied from the D-Bus callback.
 func (e *editor) registerPageListener() {
-	err := conn.RegisterPageListener(e.pageListener)
+	err := conn.RegisterPageListener(func(serial string, page int32) {
+		e.events.post(func() {
+			e.pageListener(serial, page)
+		})
+	})
 	if err != nil {
-		dialog.ShowError(err, e.win)
+		e.events.post(func() {
+			dialog.ShowError(err, e.win)
+		})
 	}
 }
 
@@ -251,7 +318,7 @@ func (e *editor) reset() {
 	}
 	e.refreshEditor()
 
-	err := conn.SetConfig(e.config)
+	err := e.pushConfig()
 	if err != nil {
 		dialog.ShowError(err, e.win)
 	}
@@ -270,12 +337,14 @@ func (e *editor) setPage(page int, pushToDbus bool) {
 	e.pageLabel.label.SetText(text)
 	e.currentDevice.Page = page
 	e.currentButton = nil
+	e.selection = nil
+	e.refreshSelectionEditor()
 	e.refresh()
 }
 
 // Save config. Used by both the toolbar action and the keyboard shortcut
 func (e *editor) saveConfig() {
-	err := conn.SetConfig(e.config)
+	err := e.pushConfig()
 	if err != nil {
 		dialog.ShowError(err, e.win)
 		return
@@ -283,60 +352,61 @@ func (e *editor) saveConfig() {
 	err = conn.CommitConfig()
 	if err != nil {
 		dialog.ShowError(err, e.win)
+		return
 	}
-}
-
-// Copy current button. Used by both the toolbar action and the keyboard shortcut
-func (e *editor) copyButton() {
-	e.copiedButton = e.currentButton
-}
-
-// Paste copied button, if any. Used by both the toolbar action and the keyboard shortcut
-func (e *editor) pasteButton() {
-	if e.copiedButton != nil {
-		e.currentButton.key = e.copiedButton.key
-		e.refreshEditor()
-	}
+	e.forgetSecrets()
+	e.saveSnapshot()
 }
 
 func (e *editor) loadToolbar() *widget.Toolbar {
 	e.pageLabel = newToolbarLabel("0")
 	return widget.NewToolbar(
-		newToolBarActionWithLabel("Preview", theme.UploadIcon(), func() {
-			err := conn.SetConfig(e.config)
+		newToolBarActionWithLabel(lang.L("Preview"), theme.UploadIcon(), func() {
+			err := e.pushConfig()
 			if err != nil {
 				dialog.ShowError(err, e.win)
 			}
 		}),
-		newToolBarActionWithLabel("Save", theme.DocumentSaveIcon(), e.saveConfig),
-		newToolBarActionWithLabel("Reload", theme.ContentUndoIcon(), func() {
+		newToolBarActionWithLabel(lang.L("Save"), theme.DocumentSaveIcon(), e.saveConfig),
+		newToolBarActionWithLabel(lang.L("Reload"), theme.ContentUndoIcon(), func() {
 			err := conn.ReloadConfig()
 			if err != nil {
 				dialog.ShowError(err, e.win)
 			}
-			c, err := conn.GetConfig()
+			c, err := fetchConfig(e.settings)
 			if err != nil {
 				dialog.ShowError(err, e.win)
+				return
 			}
 			e.config = c
+			e.committedSecrets = secretIDs(c)
+			e.undoStack = nil
+			e.ensureDecks()
 			e.refresh()
 		}),
-		newToolBarActionWithLabel("Reset", theme.DeleteIcon(), func() {
-			dialog.ShowConfirm("Reset config?", "Are you sure you want to reset?",
+		newToolBarActionWithLabel(lang.L("Reset"), theme.DeleteIcon(), func() {
+			dialog.ShowConfirm(lang.L("Reset config?"), lang.L("Are you sure you want to reset?"),
 				func(ok bool) {
 					if ok {
 						e.reset()
 					}
 				}, e.win)
 		}),
-		newToolBarActionWithLabel("Run Button", theme.MediaPlayIcon(), func() {
+		newToolBarActionWithLabel(lang.L("Run Button"), theme.MediaPlayIcon(), func() {
 			err := conn.PressButton(e.currentDevice.Serial, e.currentButton.keyID)
 			if err != nil {
-				fyne.LogError("Failed to run button press", err)
+				logError(categoryDaemon, "Failed to run button press", err)
 			}
 		}),
-		newToolBarActionWithLabel("Copy Button", theme.ContentCopyIcon(), e.copyButton),
-		newToolBarActionWithLabel("Paste Button", theme.ContentPasteIcon(), e.pasteButton),
+		newToolBarActionWithLabel(lang.L("Copy Button"), theme.ContentCopyIcon(), e.copyButton),
+		newToolBarActionWithLabel(lang.L("Paste Button"), theme.ContentPasteIcon(), e.pasteButton),
+		newToolBarActionWithLabel(lang.L("Copy Page"), theme.ContentCopyIcon(), e.copyPage),
+		newToolBarActionWithLabel(lang.L("Search"), theme.SearchIcon(), e.showSearch),
+		newToolBarActionWithLabel(lang.L("Replace"), theme.SearchReplaceIcon(), e.showFindReplace),
+		newToolBarActionWithLabel(lang.L("Undo"), theme.ContentUndoIcon(), e.undo),
+		newToolBarActionWithLabel(lang.L("History"), theme.HistoryIcon(), e.showHistory),
+		newToolBarActionWithLabel(lang.L("Templates"), theme.ListIcon(), e.toggleTemplatePanel),
+		newToolBarActionWithLabel(lang.L("Side by Side"), theme.GridIcon(), e.toggleSideBySide),
 		widget.NewToolbarSpacer(),
 		widget.NewToolbarAction(theme.MediaSkipPreviousIcon(), func() {
 			if e.currentDevice.Page == 0 {
@@ -355,80 +425,61 @@ func (e *editor) loadToolbar() *widget.Toolbar {
 		}),
 		widget.NewToolbarSpacer(),
 
-		widget.NewToolbarAction(theme.ContentAddIcon(), func() {
-			e.currentDeviceConfig.Pages = append(e.currentDeviceConfig.Pages, e.emptyPage())
-			err := conn.SetConfig(e.config)
-			if err != nil {
-				dialog.ShowError(err, e.win)
-				return
-			}
-			e.setPage(len(e.currentDeviceConfig.Pages)-1, true)
-		}),
+		widget.NewToolbarAction(theme.ContentAddIcon(), e.showNewPage),
 		widget.NewToolbarAction(theme.ContentRemoveIcon(), func() {
-			if len(e.currentDeviceConfig.Pages) == 1 {
-				e.reset()
-				return
-			}
-
-			for i := len(e.currentDeviceConfig.Pages) - 1; i > e.currentDevice.Page; i-- {
-				e.currentDeviceConfig.Pages[i-1] = e.currentDeviceConfig.Pages[i]
-			}
-			e.currentDeviceConfig.Pages = e.currentDeviceConfig.Pages[:len(e.currentDeviceConfig.Pages)-1]
-
-			e.setPage(e.currentDevice.Page-1, true)
-			err := conn.SetConfig(e.config)
-			if err != nil {
-				dialog.ShowError(err, e.win)
-				return
-			}
+			e.removePage(e.currentDevice.Page)
+		}),
+		widget.NewToolbarAction(theme.NavigateBackIcon(), func() {
+			e.movePage(e.currentDevice.Page, e.currentDevice.Page-1)
+		}),
+		widget.NewToolbarAction(theme.NavigateNextIcon(), func() {
+			e.movePage(e.currentDevice.Page, e.currentDevice.Page+1)
 		}),
+		widget.NewToolbarSeparator(),
+		newToolBarActionWithLabel(lang.L("Pages"), theme.ListIcon(), e.showPageManager),
+		newToolBarActionWithLabel(lang.L("Import"), theme.FolderOpenIcon(), e.showImport),
+		newToolBarActionWithLabel(lang.L("Copy Deck"), theme.ContentCopyIcon(), e.showCopyDeck),
+		newToolBarActionWithLabel(lang.L("Deck Settings"), theme.SettingsIcon(), e.showDeckSettings),
+		newToolBarActionWithLabel(lang.L("Diagnostics"), theme.InfoIcon(), e.showDiagnostics),
+		newToolBarActionWithLabel(lang.L("Language"), theme.ComputerIcon(), e.showLanguage),
 	)
 }
 
 func (e *editor) loadUI() fyne.CanvasObject {
+	emptyDecks := e.ensureDecks()
 	toolbar := e.loadToolbar()
-	var page api.Page
-	if len(e.currentDeviceConfig.Pages) >= 1 {
-		page = e.currentDeviceConfig.Pages[e.currentDevice.Page]
-	}
-
-	var layouts []*fyne.Container
-	for j := range e.info {
+	var views []fyne.CanvasObject
+	for _, info := range e.info {
+		var page api.Page
+		if deck := e.deckConfig(info.Serial); info.Page < len(deck.Pages) {
+			page = deck.Pages[info.Page]
+		}
 		var buttons []fyne.CanvasObject
-		for i := 0; i < e.info[j].Cols*e.info[j].Rows; i++ {
+		for i := 0; i < info.Cols*info.Rows; i++ {
 			var key api.Key
 			if i < len(page) {
 				key = page[i]
 			}
-			btn := newButton(key, i, e)
+			btn := newButton(key, i, info, e)
 			if i == 0 {
 				e.currentButton = btn
 			}
 			buttons = append(buttons, btn)
 		}
-		e.deviceButtons[e.info[j].Serial] = buttons
-		buttonGrid := fyne.NewContainerWithLayout(layout.NewGridLayout(e.info[j].Cols),
-			buttons...)
-		e.layouts[e.info[j].Serial] = buttonGrid
-		layouts = append(layouts, buttonGrid)
+		e.deviceButtons[info.Serial] = buttons
+		v := e.newDeckView(info, buttons)
+		e.views[info.Serial] = v
+		views = append(views, v.view)
 	}
 
+	templatePanel := e.loadTemplatePanel()
 	editor := e.loadEditor()
 	e.setPage(e.currentDevice.Page, false)
 
 	var deviceIDs []string
 
 	for i := range e.info {
-		deviceString := ""
-		if e.info[i].Cols == 5 {
-			deviceString = "Elgato Streamdeck Original: "
-		} else if e.info[i].Cols == 3 {
-			deviceString = "Elgato Streamdeck Mini: "
-		} else if e.info[i].Cols == 8 {
-			deviceString = "Elgato Streamdeck XL: "
-		}
-		deviceString += e.info[i].Serial
-		deviceIDs = append(deviceIDs, deviceString)
+		deviceIDs = append(deviceIDs, deviceLabel(e.info[i]))
 	}
 
 	e.deviceSelector = widget.NewSelect(deviceIDs, func(selected string) {
@@ -437,7 +488,6 @@ func (e *editor) loadUI() fyne.CanvasObject {
 			if e.info[i].Serial == serial {
 				e.currentDevice = e.info[i]
 			}
-			e.layouts[e.info[i].Serial].Hide()
 		}
 		for i := range e.config.Decks {
 			if e.config.Decks[i].Serial == serial {
@@ -445,8 +495,7 @@ func (e *editor) loadUI() fyne.CanvasObject {
 			}
 		}
 		e.buttons = e.deviceButtons[serial]
-		container := e.layouts[serial]
-		container.Show()
+		e.showDecks()
 		for i := range e.info {
 			if e.info[i].Serial == serial {
 				e.setPage(e.info[i].Page, false)
@@ -456,7 +505,7 @@ func (e *editor) loadUI() fyne.CanvasObject {
 	e.buttons = e.deviceButtons[deviceIDs[0]]
 	e.deviceSelector.SetSelectedIndex(0)
 
-	form := widget.NewForm(widget.NewFormItem("Device: ", e.deviceSelector))
+	form := widget.NewForm(widget.NewFormItem(lang.L("Device"), e.deviceSelector))
 
 	if len(deviceIDs) == 1 {
 		form.Hide()
@@ -464,14 +513,37 @@ func (e *editor) loadUI() fyne.CanvasObject {
 
 	topGrid := fyne.NewContainerWithLayout(layout.NewBorderLayout(toolbar, form, nil, nil), toolbar, form)
 
-	layoutsCont := fyne.NewContainerWithLayout(layout.NewCenterLayout())
+	e.deckRow = container.NewHBox(views...)
+	e.status = widget.NewLabel("")
+	e.status.Truncation = fyne.TextTruncateEllipsis
+	layoutsCont := container.NewBorder(nil, e.status, nil, nil, container.NewCenter(e.deckRow))
 
-	for i := range layouts {
-		layoutsCont.Add(layouts[i])
+	e.showScaffoldWizards(emptyDecks)
+
+	return fyne.NewContainerWithLayout(layout.NewBorderLayout(topGrid, editor, nil, templatePanel),
+		topGrid, editor, templatePanel, layoutsCont)
+}
+
+// deviceLabel is the name shown for a device in the device selector.
+func deviceLabel(info *api.StreamDeckInfo) string {
+	deviceString := ""
+	if info.Cols == 5 {
+		deviceString = "Elgato Streamdeck Original: "
+	} else if info.Cols == 3 {
+		deviceString = "Elgato Streamdeck Mini: "
+	} else if info.Cols == 8 {
+		deviceString = "Elgato Streamdeck XL: "
 	}
+	return deviceString + info.Serial
+}
 
-	return fyne.NewContainerWithLayout(layout.NewBorderLayout(topGrid, editor, nil, nil),
-		topGrid, editor, layoutsCont)
+func (e *editor) deviceInfo(serial string) *api.StreamDeckInfo {
+	for i := range e.info {
+		if e.info[i].Serial == serial {
+			return e.info[i]
+		}
+	}
+	return nil
 }
 
 type ToolbarActionWithLabel struct {
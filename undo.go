@@ -0,0 +1,117 @@
+package main
+
+import (
+	"github.com/unix-streamdeck/api"
+)
+
+const (
+	maxUndo = 50
+)
+
+// keyChange records one key being replaced in the config, so that it can be
+// put back by undo.
+type keyChange struct {
+	serial      string
+	page, index int
+	before      api.Key
+	after       api.Key
+}
+
+// copyKey returns a copy of key that shares no handler field maps with it.
+func copyKey(key api.Key) api.Key {
+	key.IconHandlerFields = copyFields(key.IconHandlerFields)
+	key.KeyHandlerFields = copyFields(key.KeyHandlerFields)
+	return key
+}
+
+func copyFields(fields map[string]string) map[string]string {
+	if fields == nil {
+		return nil
+	}
+	c := make(map[string]string, len(fields))
+	for k, v := range fields {
+		c[k] = v
+	}
+	return c
+}
+
+func (e *editor) deckConfig(serial string) *api.Deck {
+	for i := range e.config.Decks {
+		if e.config.Decks[i].Serial == serial {
+			return &e.config.Decks[i]
+		}
+	}
+	return nil
+}
+
+func (e *editor) setKey(serial string, page, index int, key api.Key) {
+	deck := e.deckConfig(serial)
+	if deck == nil || page >= len(deck.Pages) || index >= len(deck.Pages[page]) {
+		return
+	}
+	deck.Pages[page][index] = key
+}
+
+// applyChanges writes a batch of key changes to the config as one undo step.
+func (e *editor) applyChanges(changes []keyChange) {
+	if len(changes) == 0 {
+		return
+	}
+	for _, change := range changes {
+		e.setKey(change.serial, change.page, change.index, copyKey(change.after))
+	}
+	e.undoStack = append(e.undoStack, changes)
+	if len(e.undoStack) > maxUndo {
+		e.undoStack = e.undoStack[1:]
+	}
+	e.refresh()
+}
+
+// undo reverts the most recent batch of key changes.
+func (e *editor) undo() {
+	if len(e.undoStack) == 0 {
+		return
+	}
+	changes := e.undoStack[len(e.undoStack)-1]
+	e.undoStack = e.undoStack[:len(e.undoStack)-1]
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		e.setKey(change.serial, change.page, change.index, copyKey(change.before))
+	}
+	e.refresh()
+}
+
+// remapUndo keeps the undo steps of the deck of serial on the same pages after
+// they moved, along with the page links of the keys they put back. mapping
+// takes and returns zero based page numbers, -1 for a page that is gone;
+// changes to such pages are dropped, and so are steps left with none.
+func (e *editor) remapUndo(serial string, mapping func(page int) int) {
+	var stack [][]keyChange
+	for _, changes := range e.undoStack {
+		var kept []keyChange
+		for _, change := range changes {
+			if change.serial == serial {
+				change.page = mapping(change.page)
+				if change.page < 0 {
+					continue
+				}
+				change.before, change.after = copyKey(change.before), copyKey(change.after)
+				remapKeyPages(&change.before, mapping, serial)
+				remapKeyPages(&change.after, mapping, serial)
+			}
+			kept = append(kept, change)
+		}
+		if len(kept) > 0 {
+			stack = append(stack, kept)
+		}
+	}
+	e.undoStack = stack
+}
+
+// forgetUndo drops the undo steps of the deck of serial, whose pages were
+// replaced.
+func (e *editor) forgetUndo(serial string) {
+	e.remapUndo(serial, func(int) int {
+		return -1
+	})
+}
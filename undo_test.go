@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestUndoFollowsPages(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "one"}}, api.Page{{Text: "two"}}, api.Page{{Text: "three", SwitchPage: 2}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	edit := func(page int, text string) {
+		before := e.deckConfig("A").Pages[page][0]
+		e.applyChanges([]keyChange{{serial: "A", page: page, index: 0, before: copyKey(before), after: api.Key{Text: text}}})
+	}
+
+	edit(2, "edited")
+	e.removePage(0)
+	e.undo()
+	pages := e.deckConfig("A").Pages
+	if key := pages[1][0]; key.Text != "three" || key.SwitchPage != 1 {
+		t.Errorf("undo after removing a page before the edit put back %+v, want three linking to page 1", key)
+	}
+
+	edit(0, "edited")
+	e.movePage(0, 1)
+	e.undo()
+	pages = e.deckConfig("A").Pages
+	if pages[1][0].Text != "two" || pages[0][0].Text != "three" {
+		t.Errorf("undo after moving the edited page left %q and %q, want three and two", pages[0][0].Text, pages[1][0].Text)
+	}
+
+	edit(0, "edited")
+	e.removePage(0)
+	if len(e.undoStack) != 0 {
+		t.Errorf("%d steps left to undo after removing the edited page, want none", len(e.undoStack))
+	}
+
+	edit(0, "edited")
+	e.mergePages("A", []api.Page{{{Text: "new"}}}, true)
+	e.undo()
+	if key := e.deckConfig("A").Pages[0][0]; key.Text != "new" {
+		t.Errorf("undo after replacing the pages put back %q over the new page", key.Text)
+	}
+}
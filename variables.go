@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/unix-streamdeck/api"
+)
+
+// The daemon only ever sees expanded values. The keys that had ${var}
+// references are kept as written in unexpandedFile, by deck, page and position, and put back when the editor
+// reads the config again, as long as the daemon still has their expansion.
+const unexpandedFile = "unexpanded.json"
+
+// unexpandedKeys maps the position of a key, as serial/page/index, to the key
+// as it was written.
+type unexpandedKeys map[string]api.Key
+
+func keyPosition(serial string, page, index int) string {
+	return fmt.Sprintf("%s/%d/%d", serial, page, index)
+}
+
+var variablePattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// expandVariables replaces ${name} references in s with deck variables.
+// Other references are left as they are and returned; in a command the
+// daemon's shell expands them from its own environment.
+func expandVariables(s string, vars map[string]string) (string, []string) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var undefined []string
+	expanded := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		undefined = append(undefined, name)
+		return match
+	})
+	return expanded, undefined
+}
+
+// expandKey returns a copy of key with variables expanded in its Command,
+// Url, Text and handler fields, and whether it had any references.
+func expandKey(key api.Key, vars map[string]string) (api.Key, bool) {
+	key = copyKey(key)
+	references := false
+	expand := func(value *string) {
+		if strings.Contains(*value, "${") {
+			references = true
+			*value, _ = expandVariables(*value, vars)
+		}
+	}
+	expand(&key.Command)
+	expand(&key.Url)
+	expand(&key.Text)
+	for _, fields := range []map[string]string{key.IconHandlerFields, key.KeyHandlerFields} {
+		for name, value := range fields {
+			expand(&value)
+			fields[name] = value
+		}
+	}
+	return key, references
+}
+
+// expandConfig returns a copy of c for the daemon, and the keys that had
+// references as they were written.
+func expandConfig(c *api.Config, s *settings) (*api.Config, unexpandedKeys) {
+	expanded := &api.Config{Modules: c.Modules}
+	unexpanded := make(unexpandedKeys)
+	for _, deck := range c.Decks {
+		vars := s.deck(deck.Serial).Variables
+		d := api.Deck{Serial: deck.Serial}
+		for p, page := range deck.Pages {
+			keys := make(api.Page, len(page))
+			for i, key := range page {
+				var references bool
+				keys[i], references = expandKey(key, vars)
+				if references {
+					unexpanded[keyPosition(deck.Serial, p, i)] = copyKey(key)
+				}
+			}
+			d.Pages = append(d.Pages, keys)
+		}
+		expanded.Decks = append(expanded.Decks, d)
+	}
+	return expanded, unexpanded
+}
+
+// collapseConfig puts the references of unexpanded back into c where the
+// daemon's key is still their expansion.
+func collapseConfig(c *api.Config, s *settings, unexpanded unexpandedKeys) {
+	for _, deck := range c.Decks {
+		vars := s.deck(deck.Serial).Variables
+		for p, page := range deck.Pages {
+			for i := range page {
+				key, ok := unexpanded[keyPosition(deck.Serial, p, i)]
+				if !ok {
+					continue
+				}
+				expanded, _ := expandKey(key, vars)
+				want, err := json.Marshal(expanded)
+				if err != nil {
+					continue
+				}
+				got, err := json.Marshal(page[i])
+				if err == nil && bytes.Equal(got, want) {
+					page[i] = copyKey(key)
+				}
+			}
+		}
+	}
+}
+
+// pushConfig sends the config to the daemon with variables expanded.
+// e.config keeps the references. Secret references are sent as they are, so
+// the config the daemon commits never holds a secret.
+func (e *editor) pushConfig() error {
+	expanded, unexpanded := expandConfig(e.config, e.settings)
+	err := conn.SetConfig(expanded)
+	if err != nil {
+		return err
+	}
+	err = saveJSON(unexpandedFile, unexpanded)
+	if err != nil {
+		logError(categoryUI, "Unable to save variable references", err)
+	}
+	return nil
+}
+
+// fetchConfig reads the config from the daemon with variable references
+// restored.
+func fetchConfig(s *settings) (*api.Config, error) {
+	c, err := conn.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	unexpanded := make(unexpandedKeys)
+	err = loadJSON(unexpandedFile, &unexpanded)
+	if err != nil {
+		logError(categoryUI, "Unable to load variable references", err)
+	}
+	collapseConfig(c, s, unexpanded)
+	return c, nil
+}
+
+// variablePreview wraps an entry with a line showing its value expanded
+// with the current deck's variables, or warning of undefined ones. In a
+// shell command other references are left to the daemon's environment. The
+// returned function updates the preview and should be called on change.
+func (e *editor) variablePreview(entry fyne.CanvasObject, text string, shell bool) (fyne.CanvasObject, func(string)) {
+	preview := widget.NewLabel("")
+	preview.Wrapping = fyne.TextWrapWord
+	preview.Importance = widget.LowImportance
+	update := func(text string) {
+		if !strings.Contains(text, "${") {
+			preview.Hide()
+			return
+		}
+		expanded, undefined := expandVariables(text, e.settings.deck(e.currentDevice.Serial).Variables)
+		if len(undefined) > 0 && !shell {
+			preview.Importance = widget.WarningImportance
+			preview.SetText(lang.L("Undefined:") + " " + strings.Join(undefined, ", "))
+		} else {
+			preview.Importance = widget.LowImportance
+			preview.SetText("→ " + expanded)
+		}
+		preview.Show()
+	}
+	update(text)
+	return container.NewVBox(entry, preview), update
+}
+
+// loadVariablesUI edits the deck's variable table in place in vars.
+func loadVariablesUI(vars map[string]string) fyne.CanvasObject {
+	type row struct{ name, value string }
+	var rows []*row
+	for _, name := range sortedKeys(vars) {
+		rows = append(rows, &row{name: name, value: vars[name]})
+	}
+	sync := func() {
+		for name := range vars {
+			delete(vars, name)
+		}
+		for _, r := range rows {
+			if r.name != "" {
+				vars[r.name] = r.value
+			}
+		}
+	}
+
+	list := container.NewVBox()
+	var rebuild func()
+	rebuild = func() {
+		list.Objects = nil
+		for i, r := range rows {
+			i, r := i, r
+			name := widget.NewEntry()
+			name.SetPlaceHolder(lang.L("name"))
+			name.SetText(r.name)
+			name.OnChanged = func(text string) {
+				r.name = text
+				sync()
+			}
+			value := widget.NewEntry()
+			value.SetPlaceHolder(lang.L("value"))
+			value.SetText(r.value)
+			value.OnChanged = func(text string) {
+				r.value = text
+				sync()
+			}
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				rows = append(rows[:i], rows[i+1:]...)
+				sync()
+				rebuild()
+			})
+			list.Add(container.NewBorder(nil, nil, nil, remove, container.NewGridWithColumns(2, name, value)))
+		}
+		list.Refresh()
+	}
+	rebuild()
+
+	add := widget.NewButtonWithIcon(lang.L("Add Variable"), theme.ContentAddIcon(), func() {
+		rows = append(rows, &row{})
+		rebuild()
+	})
+	help := widget.NewLabel(lang.L("Use ${name} in commands, URLs and text. Commands can use the daemon's environment variables too."))
+	help.Wrapping = fyne.TextWrapWord
+	return container.NewBorder(help, add, nil, nil, container.NewVScroll(list))
+}
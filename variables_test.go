@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/unix-streamdeck/api"
+)
+
+func TestVariablesStayInTheEditor(t *testing.T) {
+	info, deck := testDeck("A", api.Page{{Text: "${greeting}", Command: "echo ${greeting} ${HOME}"}, {Text: "plain"}})
+	f := newFakeDaemon(&api.Config{Decks: []api.Deck{deck}}, info)
+	e, _ := newTestEditor(t, f)
+	e.settings.deck("A").Variables = map[string]string{"greeting": "hi"}
+
+	err := e.pushConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := pushedPages(f, "A")[0][0]
+	if key.Text != "hi" || key.Command != "echo hi ${HOME}" {
+		t.Errorf("daemon got %q running %q, want hi running echo hi ${HOME}", key.Text, key.Command)
+	}
+	if len(key.KeyHandlerFields) != 0 || len(key.IconHandlerFields) != 0 {
+		t.Errorf("daemon got handler fields %v and %v, want none", key.KeyHandlerFields, key.IconHandlerFields)
+	}
+
+	c, err := fetchConfig(e.settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := c.Decks[0].Pages[0][0]; key.Text != "${greeting}" || key.Command != "echo ${greeting} ${HOME}" {
+		t.Errorf("read back %q running %q, want the references", key.Text, key.Command)
+	}
+
+	f.mu.Lock()
+	f.config.Decks[0].Pages[0][0].Text = "changed"
+	f.mu.Unlock()
+	c, err = fetchConfig(e.settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := c.Decks[0].Pages[0][0]; key.Text != "changed" {
+		t.Errorf("a key changed outside the editor reads back as %q, want changed", key.Text)
+	}
+}